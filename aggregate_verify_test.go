@@ -0,0 +1,113 @@
+package bls_test
+
+import (
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+// Vectors from the consensus-spec aggregate_verify tests.
+func TestAggregateVerifyConsensusSpec(t *testing.T) {
+	publicKeys := [][]byte{
+		convertHexToPublicKey("a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a"),
+		convertHexToPublicKey("b301803f8b5ac4a1133581fc676dfedc60d891dd5fa99028805e5ea5b08d3491af75d0707adab3b70c6a6a580217bf81"),
+		convertHexToPublicKey("b53d21a4cfd562c469cc81514d4ce5a6b577d8403d32a394dc265dd190b47fa9f829fdd7963afdf972e5e77854051f6f"),
+	}
+	msgs := [][]byte{
+		convertHexToMessage("0000000000000000000000000000000000000000000000000000000000000000"),
+		convertHexToMessage("5656565656565656565656565656565656565656565656565656565656565656"),
+		convertHexToMessage("abababababababababababababababababababababababababababababababab"),
+	}
+	cases := []struct {
+		name       string
+		signature  []byte
+		publicKeys [][]byte
+		msgs       [][]byte
+		valid      bool
+		// decodes is set when the inputs are well formed, so that the pairing check is reached.
+		decodes bool
+	}{
+		{
+			name:       "aggregate_verify_valid",
+			signature:  convertHexToSignature("9104e74b9dfd3ad502f25d6a5ef57db0ed7d9a0e00f3500586d8ce44231212542fcfaf87840539b398bf07626705cf1105d246ca1062c6c2e1a53029a0f790ed5e3cb1f52f8234dc5144c45fc847c0cd37a92d68e7c5ba7c648a8a339f171244"),
+			publicKeys: publicKeys,
+			msgs:       msgs,
+			valid:      true,
+		},
+		{
+			// The spec truncates the signature, which fails decoding. The consensus-spec signature of
+			// 0x5656...56 by the first key is used instead, so that only the pairing check fails.
+			name:       "aggregate_verify_tampered_signature",
+			signature:  convertHexToSignature("882730e5d03f6b42c3abc26d3372625034e1d871b65a8a6b900a56dae22da98abbe1b68f85e49fe7652a55ec3d0591c20767677e33e5cbb1207315c41a9ac03be39c2e7668edc043d6cb1d9fd93033caa8a1c5b0e84bedaeb6c64972503a43eb"),
+			publicKeys: publicKeys,
+			msgs:       msgs,
+			decodes:    true,
+		},
+		{
+			name:       "aggregate_verify_infinite_pubkey",
+			signature:  convertHexToSignature("9104e74b9dfd3ad502f25d6a5ef57db0ed7d9a0e00f3500586d8ce44231212542fcfaf87840539b398bf07626705cf1105d246ca1062c6c2e1a53029a0f790ed5e3cb1f52f8234dc5144c45fc847c0cd37a92d68e7c5ba7c648a8a339f171244"),
+			publicKeys: append(append([][]byte{}, publicKeys...), convertHexToPublicKey("c0")),
+			msgs:       append(append([][]byte{}, msgs...), convertHexToMessage("1212121212121212121212121212121212121212121212121212121212121212")),
+		},
+		{
+			name:      "aggregate_verify_na_pubkeys_and_infinity_signature",
+			signature: bls.InfiniteSignature[:],
+		},
+		{
+			name:      "aggregate_verify_na_pubkeys_and_na_signature",
+			signature: convertHexToSignature("00"),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			valid, err := bls.AggregateVerify(c.signature, c.msgs, c.publicKeys, true)
+			if c.valid || c.decodes {
+				require.NoError(t, err)
+			}
+			require.Equal(t, c.valid, valid)
+		})
+	}
+}
+
+func TestAggregateVerifyDistinctMessages(t *testing.T) {
+	msg := convertHexToMessage("5656565656565656565656565656565656565656565656565656565656565656")
+
+	privateKey1, err := bls.GenerateKey()
+	require.NoError(t, err)
+	privateKey2, err := bls.GenerateKey()
+	require.NoError(t, err)
+
+	aggregatedSignature, err := bls.AggregateSignatures([][]byte{privateKey1.Sign(msg).Bytes(), privateKey2.Sign(msg).Bytes()})
+	require.NoError(t, err)
+	signature, err := bls.NewSignatureFromBytes(aggregatedSignature)
+	require.NoError(t, err)
+
+	publicKeys := []bls.PublicKey{privateKey1.PublicKey(), privateKey2.PublicKey()}
+	require.True(t, signature.AggregateVerify([][]byte{msg, msg}, publicKeys, false))
+	require.False(t, signature.AggregateVerify([][]byte{msg, msg}, publicKeys, true))
+
+	_, err = bls.AggregateVerify(aggregatedSignature, [][]byte{msg, msg}, [][]byte{bls.CompressPublicKey(publicKeys[0]), bls.CompressPublicKey(publicKeys[1])}, true)
+	require.ErrorIs(t, err, bls.ErrMessagesNotDistinct)
+	// Basic always requires distinct messages.
+	_, err = bls.BasicCiphersuite.AggregateVerify(aggregatedSignature, [][]byte{msg, msg}, [][]byte{bls.CompressPublicKey(publicKeys[0]), bls.CompressPublicKey(publicKeys[1])}, false)
+	require.ErrorIs(t, err, bls.ErrMessagesNotDistinct)
+}
+
+func TestAggregateVerifyAug(t *testing.T) {
+	sigs := make([][]byte, len(ciphersuiteTestVectors[1].signatures))
+	for i, signature := range ciphersuiteTestVectors[1].signatures {
+		sigs[i] = convertHexToSignature(signature)
+	}
+	aggregatedSignature, err := bls.AggregateSignatures(sigs)
+	require.NoError(t, err)
+	msgs := make([][]byte, len(ciphersuiteMessages))
+	publicKeys := make([][]byte, len(ciphersuitePublicKeys))
+	for i := range ciphersuiteMessages {
+		msgs[i] = convertHexToMessage(ciphersuiteMessages[i])
+		publicKeys[i] = convertHexToPublicKey(ciphersuitePublicKeys[i])
+	}
+	valid, err := bls.AugCiphersuite.AggregateVerify(aggregatedSignature, msgs, publicKeys, false)
+	require.NoError(t, err)
+	require.True(t, valid)
+}
@@ -0,0 +1,86 @@
+package bls
+
+import (
+	"fmt"
+
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// Group arithmetic on public keys and signatures. Results may be the point at infinity, e.g. when the only
+// signer is removed from an aggregate: such a public key never verifies and such a signature only verifies
+// against an empty set of keys.
+
+// scalarFromBytes reads a 32 bytes long big endian scalar, reduced modulo the group order.
+// It returns nil for a scalar which is zero modulo the group order.
+func scalarFromBytes(b []byte) (*blst.Scalar, error) {
+	if len(b) != scalarBytes {
+		return nil, fmt.Errorf("bls(scalar): invalid scalar length. should be %d", scalarBytes)
+	}
+	return new(blst.Scalar).FromBEndian(b), nil
+}
+
+// AddPublicKeys returns a + b.
+func AddPublicKeys(a, b PublicKey) PublicKey {
+	point := new(blst.P1)
+	point.FromAffine(a)
+	return point.AddAssign((*blst.P1Affine)(b)).ToAffine()
+}
+
+// SubtractPublicKey returns a - b, it removes the key b from the aggregate a.
+func SubtractPublicKey(a, b PublicKey) PublicKey {
+	point := new(blst.P1)
+	point.FromAffine(a)
+	return point.SubAssign((*blst.P1Affine)(b)).ToAffine()
+}
+
+// NegatePublicKey returns -p.
+func NegatePublicKey(p PublicKey) PublicKey {
+	return new(blst.P1).SubAssign((*blst.P1Affine)(p)).ToAffine()
+}
+
+// ScalePublicKey returns scalar * p, scalar is 32 bytes long and big endian.
+func ScalePublicKey(p PublicKey, scalar []byte) (PublicKey, error) {
+	s, err := scalarFromBytes(scalar)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return new(blst.P1Affine), nil
+	}
+	point := new(blst.P1)
+	point.FromAffine(p)
+	return point.MultAssign(s).ToAffine(), nil
+}
+
+// Add returns s + other.
+func (s Signature) Add(other *Signature) *Signature {
+	point := new(blst.P2)
+	point.FromAffine(s.affine)
+	return &Signature{affine: point.AddAssign(other.affine).ToAffine()}
+}
+
+// Subtract returns s - other, it removes the signature other from the aggregate s.
+func (s Signature) Subtract(other *Signature) *Signature {
+	point := new(blst.P2)
+	point.FromAffine(s.affine)
+	return &Signature{affine: point.SubAssign(other.affine).ToAffine()}
+}
+
+// Negate returns -s.
+func (s Signature) Negate() *Signature {
+	return &Signature{affine: new(blst.P2).SubAssign(s.affine).ToAffine()}
+}
+
+// Scale returns scalar * s, scalar is 32 bytes long and big endian.
+func (s Signature) Scale(scalar []byte) (*Signature, error) {
+	sc, err := scalarFromBytes(scalar)
+	if err != nil {
+		return nil, err
+	}
+	if sc == nil {
+		return NewSignature(), nil
+	}
+	point := new(blst.P2)
+	point.FromAffine(s.affine)
+	return &Signature{affine: point.MultAssign(sc).ToAffine()}, nil
+}
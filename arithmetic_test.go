@@ -0,0 +1,141 @@
+package bls_test
+
+import (
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+var infinitePublicKey = append([]byte{0xc0}, make([]byte, 47)...)
+
+// scalar returns v as a 32 bytes long big endian scalar.
+func scalar(v byte) []byte {
+	s := make([]byte, 32)
+	s[31] = v
+	return s
+}
+
+func TestSubtractFromAggregate(t *testing.T) {
+	msg := []byte("arithmetic")
+	var sigs [][]byte
+	var pubs [][]byte
+	for i := 0; i < 3; i++ {
+		privateKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		sigs = append(sigs, privateKey.Sign(msg).Bytes())
+		pubs = append(pubs, bls.CompressPublicKey(privateKey.PublicKey()))
+	}
+	aggSig, err := bls.AggregateSignatures(sigs)
+	require.NoError(t, err)
+	aggPub, err := bls.AggregatePublickKeys(pubs)
+	require.NoError(t, err)
+	expectedSig, err := bls.AggregateSignatures(sigs[1:])
+	require.NoError(t, err)
+	expectedPub, err := bls.AggregatePublickKeys(pubs[1:])
+	require.NoError(t, err)
+
+	signature, err := bls.NewSignatureFromBytes(aggSig)
+	require.NoError(t, err)
+	removedSig, err := bls.NewSignatureFromBytes(sigs[0])
+	require.NoError(t, err)
+	publicKey, err := bls.NewPublicKeyFromBytes(aggPub)
+	require.NoError(t, err)
+	removedPub, err := bls.NewPublicKeyFromBytes(pubs[0])
+	require.NoError(t, err)
+
+	signature = signature.Subtract(removedSig)
+	publicKey = bls.SubtractPublicKey(publicKey, removedPub)
+	require.Equal(t, expectedSig, signature.Bytes())
+	require.Equal(t, expectedPub, bls.CompressPublicKey(publicKey))
+	require.True(t, signature.Verify(msg, publicKey))
+
+	// Adding back gives the original aggregate.
+	require.Equal(t, aggSig, signature.Add(removedSig).Bytes())
+	require.Equal(t, aggPub, bls.CompressPublicKey(bls.AddPublicKeys(publicKey, removedPub)))
+
+	// Removing every signer leaves infinity.
+	for _, sig := range sigs[1:] {
+		s, err := bls.NewSignatureFromBytes(sig)
+		require.NoError(t, err)
+		signature = signature.Subtract(s)
+	}
+	for _, pub := range pubs[1:] {
+		p, err := bls.NewPublicKeyFromBytes(pub)
+		require.NoError(t, err)
+		publicKey = bls.SubtractPublicKey(publicKey, p)
+	}
+	require.Equal(t, bls.InfiniteSignature[:], signature.Bytes())
+	require.Equal(t, infinitePublicKey, bls.CompressPublicKey(publicKey))
+}
+
+func TestNegate(t *testing.T) {
+	privateKey, err := bls.GenerateKey()
+	require.NoError(t, err)
+	signature := privateKey.Sign([]byte("arithmetic"))
+	publicKey := privateKey.PublicKey()
+
+	require.Equal(t, bls.InfiniteSignature[:], signature.Add(signature.Negate()).Bytes())
+	require.Equal(t, infinitePublicKey, bls.CompressPublicKey(bls.AddPublicKeys(publicKey, bls.NegatePublicKey(publicKey))))
+	require.Equal(t, signature.Bytes(), signature.Negate().Negate().Bytes())
+	// Negated signatures verify against negated keys.
+	require.True(t, signature.Negate().Verify([]byte("arithmetic"), bls.NegatePublicKey(publicKey)))
+	require.False(t, signature.Negate().Verify([]byte("arithmetic"), publicKey))
+
+	require.Equal(t, bls.InfiniteSignature[:], bls.NewSignature().Negate().Bytes())
+	require.Equal(t, infinitePublicKey, bls.CompressPublicKey(bls.NegatePublicKey(bls.NewPublicKey())))
+}
+
+func TestScale(t *testing.T) {
+	msg := []byte("arithmetic")
+	privateKey, err := bls.GenerateKey()
+	require.NoError(t, err)
+	signature := privateKey.Sign(msg)
+	publicKey := privateKey.PublicKey()
+
+	doubled, err := signature.Scale(scalar(2))
+	require.NoError(t, err)
+	require.Equal(t, signature.Add(signature).Bytes(), doubled.Bytes())
+	doubledPub, err := bls.ScalePublicKey(publicKey, scalar(2))
+	require.NoError(t, err)
+	require.Equal(t, bls.CompressPublicKey(bls.AddPublicKeys(publicKey, publicKey)), bls.CompressPublicKey(doubledPub))
+	require.True(t, doubled.Verify(msg, doubledPub))
+
+	same, err := signature.Scale(scalar(1))
+	require.NoError(t, err)
+	require.Equal(t, signature.Bytes(), same.Bytes())
+
+	// Zero and the group order give infinity.
+	zero, err := signature.Scale(scalar(0))
+	require.NoError(t, err)
+	require.Equal(t, bls.InfiniteSignature[:], zero.Bytes())
+	order := convertHexToMessage("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001")
+	zero, err = signature.Scale(order)
+	require.NoError(t, err)
+	require.Equal(t, bls.InfiniteSignature[:], zero.Bytes())
+	zeroPub, err := bls.ScalePublicKey(publicKey, order)
+	require.NoError(t, err)
+	require.Equal(t, infinitePublicKey, bls.CompressPublicKey(zeroPub))
+
+	_, err = signature.Scale([]byte{2})
+	require.Error(t, err)
+	_, err = bls.ScalePublicKey(publicKey, []byte{2})
+	require.Error(t, err)
+}
+
+func TestWeightedLinearCombination(t *testing.T) {
+	msg := []byte("arithmetic")
+	combinedSig := bls.NewSignature()
+	combinedPub := bls.NewPublicKey()
+	for i := byte(1); i <= 4; i++ {
+		privateKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		sig, err := privateKey.Sign(msg).Scale(scalar(i))
+		require.NoError(t, err)
+		pub, err := bls.ScalePublicKey(privateKey.PublicKey(), scalar(i))
+		require.NoError(t, err)
+		combinedSig = combinedSig.Add(sig)
+		combinedPub = bls.AddPublicKeys(combinedPub, pub)
+	}
+	require.True(t, combinedSig.Verify(msg, combinedPub))
+}
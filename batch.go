@@ -0,0 +1,428 @@
+package bls
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// batchEntry is one signature of a batch, decoded and group checked.
+type batchEntry struct {
+	signature *blst.P2Affine
+	publicKey *blst.P1Affine
+	msg       []byte
+	dst       []byte
+	// hash is msg hashed to the curve, computed once on first use.
+	hash *blst.P2Affine
+}
+
+func (e *batchEntry) hashToCurve() *blst.P2Affine {
+	if e.hash == nil {
+		e.hash = blst.HashToG2(e.msg, e.dst).ToAffine()
+	}
+	return e.hash
+}
+
+// Minimum number of entries worth handing to a worker of its own.
+const minEntriesPerWorker = 8
+
+// verifyPool bounds the goroutines computing batch verifications, across every batch verified at once.
+type verifyPool struct {
+	workers int
+	tokens  chan struct{}
+}
+
+// verifyWorkers is the pool set by SetVerifyWorkers, nil leaves threading to blst.
+var verifyWorkers atomic.Pointer[verifyPool]
+
+// SetVerifyWorkers caps the number of cores batch verifications use: the Miller loops and scalar
+// multiplications of every batch running at once are computed by at most workers goroutines, with blst's
+// single threaded primitives, and points are decoded on the calling goroutine. 0 restores the default, where
+// blst spreads verifications over its own threads. The min-sig variant always keeps blst's threading.
+// It may be called at any time, running verifications finish with the setting they started with.
+func SetVerifyWorkers(workers int) {
+	if workers <= 0 {
+		verifyWorkers.Store(nil)
+		return
+	}
+	verifyWorkers.Store(&verifyPool{workers: workers, tokens: make(chan struct{}, workers)})
+}
+
+// p1sMult is blst.P1AffinesMult, computed on the calling goroutine when serial.
+func p1sMult(points []*blst.P1Affine, scalars []byte, bits int, serial bool) *blst.P1 {
+	if !serial {
+		return blst.P1AffinesMult(points, scalars, bits)
+	}
+	scalarLength := (bits + 7) / 8
+	acc := new(blst.P1)
+	for i, point := range points {
+		acc.MultNAccumulate(point, scalars[i*scalarLength:(i+1)*scalarLength], bits)
+	}
+	return acc
+}
+
+// p2sMult is p1sMult for G2.
+func p2sMult(points []*blst.P2Affine, scalars []byte, bits int, serial bool) *blst.P2 {
+	if !serial {
+		return blst.P2AffinesMult(points, scalars, bits)
+	}
+	scalarLength := (bits + 7) / 8
+	acc := new(blst.P2)
+	for i, point := range points {
+		acc.MultNAccumulate(point, scalars[i*scalarLength:(i+1)*scalarLength], bits)
+	}
+	return acc
+}
+
+// millerLoopN is blst.Fp12MillerLoopN, computed on the calling goroutine when serial.
+func millerLoopN(hashes []blst.P2Affine, pubKeys []blst.P1Affine, serial bool) *blst.Fp12 {
+	if !serial {
+		return blst.Fp12MillerLoopN(hashes, pubKeys)
+	}
+	lhs := blst.Fp12MillerLoop(&hashes[0], &pubKeys[0])
+	for i := 1; i < len(hashes); i++ {
+		lhs.MulAssign(blst.Fp12MillerLoop(&hashes[i], &pubKeys[i]))
+	}
+	return lhs
+}
+
+// millerLoopBatch returns the product of the Miller loops of e(r_i * P_i, H(m_i)) and the sum of r_i * S_i, the
+// scalars being bits long. Entries signing the same message share a Miller loop, their
+// scaled public keys are summed first: e(r_1 * P_1 + ... + r_k * P_k, H(m)). serial keeps blst on the calling
+// goroutine.
+func millerLoopBatch(entries []batchEntry, scalars []byte, bits int, serial bool) (*blst.Fp12, *blst.P2) {
+	scalarLength := bits / 8
+	type message struct{ msg, dst string }
+	groups := make(map[message]int, len(entries))
+	var (
+		hashes  []*blst.P2Affine
+		members [][]int
+	)
+	sigs := make([]*blst.P2Affine, len(entries))
+	for i := range entries {
+		sigs[i] = entries[i].signature
+		key := message{string(entries[i].msg), string(entries[i].dst)}
+		g, ok := groups[key]
+		if !ok {
+			g = len(members)
+			groups[key] = g
+			hashes = append(hashes, entries[i].hashToCurve())
+			members = append(members, nil)
+		} else if entries[i].hash == nil {
+			entries[i].hash = hashes[g]
+		}
+		members[g] = append(members[g], i)
+	}
+	loopHashes := make([]blst.P2Affine, len(members))
+	pubKeys := make([]blst.P1Affine, len(members))
+	for g, group := range members {
+		loopHashes[g] = *hashes[g]
+		if len(group) == 1 {
+			i := group[0]
+			pubKey := new(blst.P1)
+			pubKey.FromAffine(entries[i].publicKey)
+			pubKeys[g] = *pubKey.MultAssign(scalars[i*scalarLength : (i+1)*scalarLength]).ToAffine()
+			continue
+		}
+		points := make([]*blst.P1Affine, len(group))
+		groupScalars := make([]byte, 0, len(group)*scalarLength)
+		for j, i := range group {
+			points[j] = entries[i].publicKey
+			groupScalars = append(groupScalars, scalars[i*scalarLength:(i+1)*scalarLength]...)
+		}
+		pubKeys[g] = *p1sMult(points, groupScalars, bits, serial).ToAffine()
+	}
+	return millerLoopN(loopHashes, pubKeys, serial), p2sMult(sigs, scalars, bits, serial)
+}
+
+// Number of entries whose Miller loops run between two checks of the context.
+const batchChunkSize = 64
+
+// millerLoopChunks is millerLoopBatch run by chunks of batchChunkSize entries, checking ctx between them.
+func millerLoopChunks(ctx context.Context, entries []batchEntry, scalars []byte, bits int, serial bool) (*blst.Fp12, *blst.P2, error) {
+	if ctx.Done() == nil {
+		lhs, sig := millerLoopBatch(entries, scalars, bits, serial)
+		return lhs, sig, nil
+	}
+	scalarLength := bits / 8
+	var (
+		lhs *blst.Fp12
+		sig *blst.P2
+	)
+	for start := 0; start < len(entries); start += batchChunkSize {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		end := min(start+batchChunkSize, len(entries))
+		chunkLhs, chunkSig := millerLoopBatch(entries[start:end], scalars[start*scalarLength:end*scalarLength], bits, serial)
+		if lhs == nil {
+			lhs, sig = chunkLhs, chunkSig
+		} else {
+			lhs.MulAssign(chunkLhs)
+			sig.AddAssign(chunkSig)
+		}
+	}
+	return lhs, sig, nil
+}
+
+// verifyBatch verifies entries at once with fresh random scalars. Hashed messages are kept in the entries, so
+// that verifying the halves of a batch again only costs Miller loops and scalar multiplications.
+// e(r_1 * P_1, H(m_1)) * ... * e(r_n * P_n, H(m_n)) = e(G, r_1 * S_1 + ... + r_n * S_n)
+func verifyBatch(entries []batchEntry) (bool, error) {
+	return verifyBatchContext(context.Background(), entries)
+}
+
+// verifyBatchContext is verifyBatch, abandoned once ctx is done. With several workers, each one computes the
+// Miller loops of a chunk of the batch and the products are combined in a single final exponentiation.
+func verifyBatchContext(ctx context.Context, entries []batchEntry) (bool, error) {
+	lhs, sig, err := batchProducts(ctx, entries)
+	if err != nil {
+		return false, err
+	}
+	rhs := blst.Fp12MillerLoop(sig.ToAffine(), blst.P1Generator().ToAffine())
+	return blst.Fp12FinalVerify(lhs, rhs), nil
+}
+
+// batchProducts returns the product of the Miller loops of e(r_i * P_i, H(m_i)) and the sum of r_i * S_i of a
+// batch, r_i being the scalar of index i whatever the split across the verify workers.
+func batchProducts(ctx context.Context, entries []batchEntry) (*blst.Fp12, *blst.P2, error) {
+	scalars, bits, err := batchScalars(len(entries), entriesTranscript(entries))
+	if err != nil {
+		return nil, nil, err
+	}
+	scalarLength := bits / 8
+	pool := verifyWorkers.Load()
+	workers := 1
+	if pool != nil {
+		workers = max(min(pool.workers, (len(entries)+minEntriesPerWorker-1)/minEntriesPerWorker), 1)
+	}
+
+	chunkSize := (len(entries) + workers - 1) / workers
+	// Rounding the chunks up may leave the last workers without entries, e.g. 81 entries across 10 workers.
+	workers = (len(entries) + chunkSize - 1) / chunkSize
+	loops := make([]*blst.Fp12, workers)
+	sigs := make([]*blst.P2, workers)
+	errs := make([]error, workers)
+	work := func(w int) {
+		if pool != nil {
+			select {
+			case pool.tokens <- struct{}{}:
+				defer func() { <-pool.tokens }()
+			case <-ctx.Done():
+				errs[w] = ctx.Err()
+				return
+			}
+		}
+		start, end := w*chunkSize, min((w+1)*chunkSize, len(entries))
+		loops[w], sigs[w], errs[w] = millerLoopChunks(ctx, entries[start:end], scalars[start*scalarLength:end*scalarLength], bits, pool != nil)
+	}
+	if workers == 1 {
+		work(0)
+	} else {
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				work(w)
+			}(w)
+		}
+		wg.Wait()
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	lhs, sig := loops[0], sigs[0]
+	for w := 1; w < workers; w++ {
+		lhs.MulAssign(loops[w])
+		sig.AddAssign(sigs[w])
+	}
+	return lhs, sig, nil
+}
+
+// verifyMultipleSignaturesBatch is verifyMultipleSignatures through verifyBatch, which splits the batch across
+// the verify workers and groups repeated messages.
+func verifyMultipleSignaturesBatch(sigs []*blst.P2Affine, pubKeys []*blst.P1Affine, msgs [][]byte, dst []byte, augs [][]byte) (bool, error) {
+	entries := make([]batchEntry, len(sigs))
+	for i, sig := range sigs {
+		msg := msgs[i]
+		if augs != nil {
+			msg = append(append([]byte{}, augs[i]...), msg...)
+		}
+		entries[i] = batchEntry{signature: sig, publicKey: pubKeys[i], msg: msg, dst: dst}
+	}
+	return verifyBatch(entries)
+}
+
+// bisectBatch appends to invalid the indices of the invalid entries. When knownInvalid is set the batch
+// already failed as a whole, so it is split without being verified again.
+func bisectBatch(entries []batchEntry, indices []int, knownInvalid bool, invalid []int) ([]int, error) {
+	if !knownInvalid {
+		valid, err := verifyBatch(entries)
+		if err != nil {
+			return nil, err
+		}
+		if valid {
+			return invalid, nil
+		}
+	}
+	if len(entries) == 1 {
+		return append(invalid, indices[0]), nil
+	}
+	half := len(entries) / 2
+	invalidBefore := len(invalid)
+	invalid, err := bisectBatch(entries[:half], indices[:half], false, invalid)
+	if err != nil {
+		return nil, err
+	}
+	// If the left half is valid the invalid signatures are in the right half, no need to verify it whole.
+	return bisectBatch(entries[half:], indices[half:], len(invalid) == invalidBefore, invalid)
+}
+
+// decodeBatchEntries decodes a batch, signatures and public keys are subgroup checked at once. The indices of
+// the signatures which cannot be decoded are returned in invalid, the others along with their entries.
+func decodeBatchEntries(sigs [][]byte, msgs [][]byte, pubKeys [][]byte) ([]batchEntry, []int, []int, error) {
+	signatures := make([]*blst.P2Affine, len(sigs))
+	for i := range sigs {
+		signatures[i] = new(blst.P2Affine).Uncompress(sigs[i])
+	}
+	return decodeBatchKeys(signatures, sigs, msgs, pubKeys)
+}
+
+// decodeBatchKeys is decodeBatchEntries for signatures decompressed already, nil when they cannot be. sigs are
+// the encoded signatures to subgroup check, nil if signatures are checked already.
+func decodeBatchKeys(signatures []*blst.P2Affine, sigs [][]byte, msgs [][]byte, pubKeys [][]byte) ([]batchEntry, []int, []int, error) {
+	var (
+		invalid       []int
+		candidates    []int
+		publicKeys    = make([]*blst.P1Affine, len(signatures))
+		uncheckedKeys []*blst.P1Affine
+		encodedKeys   [][]byte
+		keyIndices    []int
+	)
+	for i := range signatures {
+		publicKeys[i] = pkCache.getAffineFromCache(pubKeys[i])
+		cached := publicKeys[i] != nil
+		if !cached {
+			// Subgroup check NOT done when decompressing pubkey.
+			publicKeys[i] = new(blst.P1Affine).Uncompress(pubKeys[i])
+		}
+		if signatures[i] == nil || publicKeys[i] == nil || (!cached && pubKeys[i][0]&infinityFlag != 0) {
+			invalid = append(invalid, i)
+			continue
+		}
+		candidates = append(candidates, i)
+		if !cached {
+			uncheckedKeys = append(uncheckedKeys, publicKeys[i])
+			encodedKeys = append(encodedKeys, pubKeys[i])
+			keyIndices = append(keyIndices, i)
+		}
+	}
+	rejected := make(map[int]bool)
+	if sigs != nil {
+		uncheckedSigs := make([]*blst.P2Affine, len(candidates))
+		encodedSigs := make([][]byte, len(candidates))
+		for j, i := range candidates {
+			uncheckedSigs[j] = signatures[i]
+			encodedSigs[j] = sigs[i]
+		}
+		offenders, err := invalidG2(uncheckedSigs, encodedSigs)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		for _, j := range offenders {
+			rejected[candidates[j]] = true
+		}
+	}
+	offenders, err := invalidG1(uncheckedKeys, encodedKeys)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, j := range offenders {
+		rejected[keyIndices[j]] = true
+	}
+	entries := make([]batchEntry, 0, len(candidates))
+	indices := make([]int, 0, len(candidates))
+	for _, i := range candidates {
+		if rejected[i] {
+			invalid = append(invalid, i)
+			continue
+		}
+		entries = append(entries, batchEntry{signature: signatures[i], publicKey: publicKeys[i], msg: msgs[i], dst: eth2Curve})
+		indices = append(indices, i)
+	}
+	return entries, indices, invalid, nil
+}
+
+// VerifyMultipleSignaturesBisect verifies a batch like VerifyMultipleSignatures and returns the indices of the
+// invalid signatures, none if the batch is valid. On failure the batch is split in halves, each verified again
+// with fresh randomness, so k invalid signatures out of n cost about k*log2(n) batch verifications instead of n
+// single ones. Signatures and public keys which cannot be decoded are reported invalid too.
+func VerifyMultipleSignaturesBisect(sigs [][]byte, msgs [][]byte, pubKeys [][]byte) ([]int, error) {
+	length := len(sigs)
+	if length != len(pubKeys) || length != len(msgs) {
+		return nil, errors.Errorf("provided signatures, pubkeys and messages have differing lengths. S: %d, P: %d,M %d",
+			length, len(pubKeys), len(msgs))
+	}
+	return verifyBisect(sigs, msgs, pubKeys, func(remaining []int) ([]batchEntry, []int, []int, error) {
+		if remaining != nil {
+			sigs, msgs, pubKeys = pick(sigs, remaining), pick(msgs, remaining), pick(pubKeys, remaining)
+		}
+		return decodeBatchEntries(sigs, msgs, pubKeys)
+	})
+}
+
+// verifyBisect bisects the tuples of a batch which did not verify before, as decoded by decode like
+// decodeBatchEntries given their indices, or nil for the whole batch when the cache is disabled. The valid
+// tuples are cached and the invalid ones reported with their indices in the whole batch.
+func verifyBisect(sigs [][]byte, msgs [][]byte, pubKeys [][]byte, decode func(remaining []int) ([]batchEntry, []int, []int, error)) ([]int, error) {
+	keys, remaining := verifications.lookupBatch(eth2Curve, sigs, msgs, pubKeys)
+	if keys != nil && len(remaining) == 0 {
+		return nil, nil
+	}
+	entries, indices, invalid, err := decode(remaining)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		invalid, err = bisectBatch(entries, indices, false, invalid)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Ints(invalid)
+	if keys == nil {
+		return invalid, nil
+	}
+	// Cache the valid tuples and map the indices back to the whole batch.
+	for i, next := 0, 0; i < len(remaining); i++ {
+		if next < len(invalid) && invalid[next] == i {
+			invalid[next] = remaining[i]
+			next++
+			continue
+		}
+		verifications.add(keys[i])
+	}
+	return invalid, nil
+}
+
+// VerifySameMessageSignatures verifies signatures of the same message under different public keys, such as
+// unaggregated attestations of the same data, and returns the indices of the invalid ones like
+// VerifyMultipleSignaturesBisect. It checks e(r_1 * S_1 + ... + r_n * S_n, G) = e(r_1 * P_1 + ... + r_n * P_n, H(m)),
+// two Miller loops whatever the number of signatures, and bisects the batch on failure.
+func VerifySameMessageSignatures(sigs [][]byte, msg []byte, pubKeys [][]byte) ([]int, error) {
+	msgs := make([][]byte, len(sigs))
+	for i := range msgs {
+		msgs[i] = msg
+	}
+	return VerifyMultipleSignaturesBisect(sigs, msgs, pubKeys)
+}
@@ -0,0 +1,156 @@
+package bls_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+// generateBatch returns n signatures of distinct messages, the ones at invalid indices sign another message.
+func generateBatch(t testing.TB, n int, invalid ...int) (sigs, msgs, pubKeys [][]byte) {
+	for i := 0; i < n; i++ {
+		privateKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		msg := []byte(fmt.Sprintf("attestation %d", i))
+		sigs = append(sigs, privateKey.Sign(msg).Bytes())
+		msgs = append(msgs, msg)
+		pubKeys = append(pubKeys, bls.CompressPublicKey(privateKey.PublicKey()))
+	}
+	for _, i := range invalid {
+		msgs[i] = []byte("tampered")
+	}
+	return sigs, msgs, pubKeys
+}
+
+func TestVerifyMultipleSignaturesBisect(t *testing.T) {
+	for _, invalid := range [][]int{nil, {0}, {127}, {5, 6}, {1, 64, 100}, {0, 31, 32, 33, 63, 64, 95, 96}} {
+		sigs, msgs, pubKeys := generateBatch(t, 128, invalid...)
+		found, err := bls.VerifyMultipleSignaturesBisect(sigs, msgs, pubKeys)
+		require.NoError(t, err)
+		if invalid == nil {
+			require.Empty(t, found)
+		} else {
+			require.Equal(t, invalid, found)
+		}
+	}
+}
+
+func TestVerifyMultipleSignaturesBisectUndecodable(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 4, 2)
+	sigs[0] = sigs[0][:10]
+	pubKeys[3] = append([]byte{0xc0}, make([]byte, 47)...)
+	found, err := bls.VerifyMultipleSignaturesBisect(sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 2, 3}, found)
+
+	_, err = bls.VerifyMultipleSignaturesBisect(sigs, msgs[:3], pubKeys)
+	require.Error(t, err)
+
+	found, err = bls.VerifyMultipleSignaturesBisect(nil, nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, found)
+}
+
+func BenchmarkVerifyMultipleSignaturesBisect(b *testing.B) {
+	sigs, msgs, pubKeys := generateBatch(b, 128, 77)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bls.VerifyMultipleSignaturesBisect(sigs, msgs, pubKeys)
+	}
+}
+
+// BenchmarkVerifyMultipleSignaturesThenOneByOne is the baseline for BenchmarkVerifyMultipleSignaturesBisect,
+// a failed batch is verified again one signature at a time.
+func BenchmarkVerifyMultipleSignaturesThenOneByOne(b *testing.B) {
+	sigs, msgs, pubKeys := generateBatch(b, 128, 77)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if valid, _ := bls.VerifyMultipleSignatures(sigs, msgs, pubKeys); valid {
+			continue
+		}
+		for j := range sigs {
+			bls.Verify(sigs[j], msgs[j], pubKeys[j])
+		}
+	}
+}
+
+// generateSameMessageBatch returns n signatures of the same message, the invalid ones sign another message.
+func generateSameMessageBatch(t testing.TB, n int, invalid ...int) (sigs [][]byte, msg []byte, pubKeys [][]byte) {
+	msg = []byte("attestation data")
+	for i := 0; i < n; i++ {
+		privateKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		sigs = append(sigs, privateKey.Sign(msg).Bytes())
+		pubKeys = append(pubKeys, bls.CompressPublicKey(privateKey.PublicKey()))
+	}
+	for _, i := range invalid {
+		privateKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		sigs[i] = privateKey.Sign([]byte("other attestation data")).Bytes()
+		pubKeys[i] = bls.CompressPublicKey(privateKey.PublicKey())
+	}
+	return sigs, msg, pubKeys
+}
+
+func TestVerifySameMessageSignatures(t *testing.T) {
+	for _, invalid := range [][]int{nil, {0}, {63}, {10, 11, 40}} {
+		sigs, msg, pubKeys := generateSameMessageBatch(t, 64, invalid...)
+		found, err := bls.VerifySameMessageSignatures(sigs, msg, pubKeys)
+		require.NoError(t, err)
+		if invalid == nil {
+			require.Empty(t, found)
+		} else {
+			require.Equal(t, invalid, found)
+		}
+
+		msgs := make([][]byte, len(sigs))
+		for i := range msgs {
+			msgs[i] = msg
+		}
+		valid, err := bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+		require.NoError(t, err)
+		require.Equal(t, invalid == nil, valid)
+	}
+}
+
+func TestVerifyMultipleSignaturesRepeatedMessages(t *testing.T) {
+	sameSigs, msg, samePubKeys := generateSameMessageBatch(t, 20)
+	sigs, msgs, pubKeys := generateBatch(t, 10)
+	for i := range sameSigs {
+		sigs = append(sigs, sameSigs[i])
+		msgs = append(msgs, msg)
+		pubKeys = append(pubKeys, samePubKeys[i])
+	}
+	valid, err := bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	// Swapping the signatures of two signers of the same message breaks the batch.
+	sigs[12], sigs[13] = sigs[13], sigs[12]
+	valid, err = bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.False(t, valid)
+	found, err := bls.VerifyMultipleSignaturesBisect(sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.Equal(t, []int{12, 13}, found)
+}
+
+func BenchmarkVerifySameMessageSignatures(b *testing.B) {
+	sigs, msg, pubKeys := generateSameMessageBatch(b, 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bls.VerifySameMessageSignatures(sigs, msg, pubKeys)
+	}
+}
+
+// BenchmarkVerifyMultipleSignaturesDistinctMessages is the baseline for BenchmarkVerifySameMessageSignatures,
+// one Miller loop per signature.
+func BenchmarkVerifyMultipleSignaturesDistinctMessages(b *testing.B) {
+	sigs, msgs, pubKeys := generateBatch(b, 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	}
+}
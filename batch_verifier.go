@@ -0,0 +1,333 @@
+package bls
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Lane is a queue of a BatchVerifier, lanes with a lower index are verified first.
+type Lane int
+
+// Lanes of the verifier returned by NewPriorityBatchVerifier with DefaultLanes.
+const (
+	LaneBlock Lane = iota
+	LaneAggregate
+	LaneAttestation
+	LaneSyncMessage
+)
+
+// OverloadPolicy tells what a full lane does with new signatures.
+type OverloadPolicy int
+
+const (
+	// RejectWhenFull refuses new signatures with ErrLaneFull.
+	RejectWhenFull OverloadPolicy = iota
+	// DropOldestWhenFull drops the oldest pending signature, reported with ErrSignatureDropped, to make room.
+	DropOldestWhenFull
+)
+
+// LaneConfig configures a lane of a BatchVerifier.
+type LaneConfig struct {
+	// QueueSize bounds the number of pending signatures, 0 means unbounded.
+	QueueSize int
+	// Latency is the longest a signature should wait before its batch is verified.
+	Latency time.Duration
+	Policy  OverloadPolicy
+}
+
+// DefaultLanes returns the configuration of LaneBlock, LaneAggregate, LaneAttestation and LaneSyncMessage.
+// Blocks are never dropped, gossip is dropped oldest first when the node falls behind.
+func DefaultLanes() []LaneConfig {
+	return []LaneConfig{
+		LaneBlock:       {QueueSize: 128, Latency: time.Millisecond, Policy: RejectWhenFull},
+		LaneAggregate:   {QueueSize: 1024, Latency: 10 * time.Millisecond, Policy: DropOldestWhenFull},
+		LaneAttestation: {QueueSize: 8192, Latency: 50 * time.Millisecond, Policy: DropOldestWhenFull},
+		LaneSyncMessage: {QueueSize: 4096, Latency: 100 * time.Millisecond, Policy: DropOldestWhenFull},
+	}
+}
+
+// verifyJob is a signature set submitted to a BatchVerifier, callback receives its result.
+type verifyJob struct {
+	// set is decoded from signature, msg and publicKey when nil.
+	set       *SignatureSet
+	signature SignatureSource
+	msg       []byte
+	publicKey []byte
+	submitted time.Time
+	callback  func(valid bool, err error)
+}
+
+type lane struct {
+	config LaneConfig
+	queue  []*verifyJob
+}
+
+// BatchVerifier coalesces signatures submitted from many goroutines into batches verified at once. A batch is
+// verified once batchSize signatures are pending or a signature waited for the latency of its lane, it is filled
+// from the lanes in priority order so that every lane shares the same multi-pairing.
+// When a batch fails, it is bisected to find out which signatures are invalid.
+type BatchVerifier struct {
+	batchSize int
+	lanes     []*lane
+	pending   int
+	closed    bool
+
+	mu   sync.Mutex
+	wake chan struct{}
+	done chan struct{}
+}
+
+// NewBatchVerifier starts a batch verifier with a single unbounded lane, Close must be called to stop it.
+func NewBatchVerifier(batchSize int, latency time.Duration) *BatchVerifier {
+	return NewPriorityBatchVerifier(batchSize, []LaneConfig{{Latency: latency}})
+}
+
+// NewPriorityBatchVerifier starts a batch verifier with the given lanes, lanes[0] has the highest priority.
+// Close must be called to stop it.
+func NewPriorityBatchVerifier(batchSize int, lanes []LaneConfig) *BatchVerifier {
+	v := &BatchVerifier{
+		batchSize: max(batchSize, 1),
+		wake:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	for _, config := range lanes {
+		v.lanes = append(v.lanes, &lane{config: config})
+	}
+	go v.run()
+	return v
+}
+
+// Verify submits a signature to the first lane, its result is sent on the returned channel.
+func (v *BatchVerifier) Verify(signature []byte, msg []byte, publicKey []byte) (<-chan bool, error) {
+	result := make(chan bool, 1)
+	if err := v.VerifyCallback(signature, msg, publicKey, func(valid bool) { result <- valid }); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// VerifyCallback submits a signature to the first lane, callback is called with its result from the verifier
+// goroutine. A signature dropped by the lane is reported invalid, VerifyLaneCallback tells them apart.
+func (v *BatchVerifier) VerifyCallback(signature []byte, msg []byte, publicKey []byte, callback func(valid bool)) error {
+	return v.VerifyLaneCallback(0, signature, msg, publicKey, func(valid bool, _ error) { callback(valid) })
+}
+
+// VerifyLaneCallback submits a signature to a lane, callback is called with its result from the verifier
+// goroutine, or with ErrSignatureDropped if the lane dropped it.
+func (v *BatchVerifier) VerifyLaneCallback(l Lane, signature []byte, msg []byte, publicKey []byte, callback func(valid bool, err error)) error {
+	return v.submit(l, &verifyJob{signature: rawSignature(signature), msg: msg, publicKey: publicKey, callback: callback})
+}
+
+// VerifyLazyLaneCallback is VerifyLaneCallback for a lazy signature, decoded along with the rest of its batch.
+func (v *BatchVerifier) VerifyLazyLaneCallback(l Lane, signature *LazySignature, msg []byte, publicKey []byte, callback func(valid bool, err error)) error {
+	return v.submit(l, &verifyJob{signature: signature, msg: msg, publicKey: publicKey, callback: callback})
+}
+
+// VerifySetLaneCallback submits a signature set to a lane, so that aggregate signatures are batched along with
+// single ones. callback is called like for VerifyLaneCallback.
+func (v *BatchVerifier) VerifySetLaneCallback(l Lane, set *SignatureSet, callback func(valid bool, err error)) error {
+	return v.submit(l, &verifyJob{set: set, callback: callback})
+}
+
+func (v *BatchVerifier) submit(l Lane, job *verifyJob) error {
+	if l < 0 || int(l) >= len(v.lanes) {
+		return fmt.Errorf("bls(signature): unknown verification lane %d", l)
+	}
+	job.submitted = time.Now()
+
+	v.mu.Lock()
+	if v.closed {
+		v.mu.Unlock()
+		return ErrBatchVerifierClosed
+	}
+	var dropped *verifyJob
+	ln := v.lanes[l]
+	if ln.config.QueueSize > 0 && len(ln.queue) >= ln.config.QueueSize {
+		if ln.config.Policy == RejectWhenFull {
+			v.mu.Unlock()
+			return ErrLaneFull
+		}
+		dropped = ln.queue[0]
+		ln.queue = ln.queue[1:]
+		v.pending--
+	}
+	ln.queue = append(ln.queue, job)
+	v.pending++
+	v.mu.Unlock()
+
+	if dropped != nil {
+		dropped.callback(false, ErrSignatureDropped)
+	}
+	v.signal()
+	return nil
+}
+
+// Close verifies the pending signatures and stops the verifier.
+func (v *BatchVerifier) Close() {
+	v.mu.Lock()
+	v.closed = true
+	v.mu.Unlock()
+	v.signal()
+	<-v.done
+}
+
+func (v *BatchVerifier) signal() {
+	select {
+	case v.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (v *BatchVerifier) run() {
+	defer close(v.done)
+	for {
+		batch, wait, stop := v.nextBatch(time.Now())
+		if batch != nil {
+			verifyJobs(batch)
+			continue
+		}
+		if stop {
+			return
+		}
+		if wait == 0 {
+			<-v.wake
+			continue
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-v.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// nextBatch pops the next batch to verify, if one is due. Otherwise it returns how long to wait for the next
+// latency deadline, 0 if nothing is pending, and whether the verifier is closed and drained.
+func (v *BatchVerifier) nextBatch(now time.Time) ([]*verifyJob, time.Duration, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.pending == 0 {
+		return nil, 0, v.closed
+	}
+	due := v.closed || v.pending >= v.batchSize
+	var wait time.Duration
+	for _, ln := range v.lanes {
+		if len(ln.queue) == 0 {
+			continue
+		}
+		remaining := ln.queue[0].submitted.Add(ln.config.Latency).Sub(now)
+		if remaining <= 0 {
+			due = true
+			break
+		}
+		if wait == 0 || remaining < wait {
+			wait = remaining
+		}
+	}
+	if !due {
+		return nil, wait, false
+	}
+	batch := make([]*verifyJob, 0, min(v.pending, v.batchSize))
+	for _, ln := range v.lanes {
+		taken := min(len(ln.queue), v.batchSize-len(batch))
+		batch = append(batch, ln.queue[:taken]...)
+		ln.queue = ln.queue[taken:]
+	}
+	v.pending -= len(batch)
+	return batch, 0, false
+}
+
+// verifyJobs verifies a batch and reports the result of every job. If the batch cannot be verified, e.g. when
+// the randomness source fails, the error is reported to every job which could be decoded.
+func verifyJobs(jobs []*verifyJob) {
+	results := make([]bool, len(jobs))
+	cached := make([]bool, len(jobs))
+	keys := make([][32]byte, len(jobs))
+	entries := make([]batchEntry, 0, len(jobs))
+	indices := make([]int, 0, len(jobs))
+	var lazy []SignatureSource
+	for i, job := range jobs {
+		if keys[i], cached[i] = job.lookup(); cached[i] {
+			continue
+		}
+		if job.set != nil {
+			lazy = append(lazy, job.set.Signature)
+		} else {
+			lazy = append(lazy, job.signature)
+		}
+	}
+	decodeLazySources(lazy)
+	for i, job := range jobs {
+		if cached[i] {
+			results[i] = true
+			continue
+		}
+		set := job.set
+		if set == nil {
+			if missingSignature(job.signature) {
+				continue
+			}
+			sig, err := job.signature.Signature()
+			if err != nil {
+				continue
+			}
+			pk, err := newPublicKeyFromBytes(job.publicKey, false)
+			if err != nil {
+				continue
+			}
+			set = &SignatureSet{Signature: sig, Message: job.msg, PublicKeys: []PublicKey{pk}}
+		}
+		entry, ok := set.batchEntry()
+		if !ok {
+			continue
+		}
+		entries = append(entries, entry)
+		indices = append(indices, i)
+		results[i] = true
+	}
+	var err error
+	if len(entries) > 0 {
+		var invalid []int
+		invalid, err = bisectBatch(entries, indices, false, nil)
+		for _, i := range invalid {
+			results[i] = false
+		}
+	}
+	for i, job := range jobs {
+		// results[i] is still set for every decoded job if the batch failed with an error.
+		if err != nil && results[i] && !cached[i] {
+			job.callback(false, err)
+			continue
+		}
+		if results[i] && !cached[i] {
+			verifications.add(keys[i])
+		}
+		job.callback(results[i], nil)
+	}
+}
+
+// signatureSetDST keys the tuples of signature sets apart from those of VerifyAggregate. The keys of a set are
+// not validated again, e.g. a point at infinity among them is aggregated, so a valid set does not make the same
+// tuple valid for VerifyAggregate.
+var signatureSetDST = append([]byte("SIGNATURE_SET_"), eth2Curve...)
+
+// lookup is verificationCache.lookup for a job. Single signatures share the keys of Verify, whose rules they
+// follow, sets are keyed by their compressed signature and public keys under signatureSetDST.
+func (job *verifyJob) lookup() ([32]byte, bool) {
+	if job.set == nil {
+		if !verifications.enabled.Load() || missingSignature(job.signature) {
+			return [32]byte{}, false
+		}
+		return verifications.lookup(eth2Curve, job.signature.Bytes(), job.msg, job.publicKey)
+	}
+	if !verifications.enabled.Load() || missingSignature(job.set.Signature) {
+		return [32]byte{}, false
+	}
+	publicKeys := make([][]byte, len(job.set.PublicKeys))
+	for i, pk := range job.set.PublicKeys {
+		publicKeys[i] = CompressPublicKey(pk)
+	}
+	return verifications.lookup(signatureSetDST, job.set.Signature.Bytes(), job.set.Message, publicKeys...)
+}
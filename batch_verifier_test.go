@@ -0,0 +1,157 @@
+package bls_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchVerifier(t *testing.T) {
+	invalid := map[int]bool{3: true, 17: true, 40: true}
+	sigs, msgs, pubKeys := generateBatch(t, 64, 3, 17, 40)
+	sigs[50] = sigs[50][:95]
+	invalid[50] = true
+
+	v := bls.NewBatchVerifier(16, 50*time.Millisecond)
+	defer v.Close()
+	var wg sync.WaitGroup
+	for i := range sigs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := v.Verify(sigs[i], msgs[i], pubKeys[i])
+			require.NoError(t, err)
+			require.Equal(t, !invalid[i], <-result, "signature %d", i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestBatchVerifierLatency(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 2)
+	v := bls.NewBatchVerifier(1000, 10*time.Millisecond)
+	defer v.Close()
+
+	// The batch is far from full, the deadline flushes it.
+	results := make(chan bool, 2)
+	for i := range sigs {
+		require.NoError(t, v.VerifyCallback(sigs[i], msgs[i], pubKeys[i], func(valid bool) { results <- valid }))
+	}
+	for range sigs {
+		select {
+		case valid := <-results:
+			require.True(t, valid)
+		case <-time.After(5 * time.Second):
+			t.Fatal("batch was not flushed after the latency deadline")
+		}
+	}
+}
+
+func TestBatchVerifierClose(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 1)
+	v := bls.NewBatchVerifier(1000, time.Hour)
+	result, err := v.Verify(sigs[0], msgs[0], pubKeys[0])
+	require.NoError(t, err)
+	// Pending signatures are verified on close.
+	v.Close()
+	require.True(t, <-result)
+
+	_, err = v.Verify(sigs[0], msgs[0], pubKeys[0])
+	require.ErrorIs(t, err, bls.ErrBatchVerifierClosed)
+	v.Close()
+}
+
+type laneResult struct {
+	index int
+	valid bool
+	err   error
+}
+
+func TestBatchVerifierPriority(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 2)
+	v := bls.NewPriorityBatchVerifier(2, bls.DefaultLanes())
+	defer v.Close()
+
+	results := make(chan laneResult, 2)
+	submit := func(lane bls.Lane, i int) {
+		require.NoError(t, v.VerifyLaneCallback(lane, sigs[i], msgs[i], pubKeys[i], func(valid bool, err error) {
+			results <- laneResult{index: i, valid: valid, err: err}
+		}))
+	}
+	// The attestation waits, the block fills the batch and is verified first.
+	submit(bls.LaneAttestation, 0)
+	submit(bls.LaneBlock, 1)
+	for _, expected := range []int{1, 0} {
+		result := <-results
+		require.Equal(t, expected, result.index)
+		require.True(t, result.valid)
+		require.NoError(t, result.err)
+	}
+}
+
+func TestBatchVerifierLaneLatency(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 2)
+	v := bls.NewPriorityBatchVerifier(100, []bls.LaneConfig{{Latency: time.Millisecond}, {Latency: time.Hour}})
+	defer v.Close()
+
+	results := make(chan laneResult, 2)
+	require.NoError(t, v.VerifyLaneCallback(1, sigs[0], msgs[0], pubKeys[0], func(valid bool, err error) {
+		results <- laneResult{index: 0, valid: valid, err: err}
+	}))
+	select {
+	case <-results:
+		t.Fatal("the slow lane was verified before its latency target")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// The fast lane meets its latency target and takes the slow lane along in its batch.
+	require.NoError(t, v.VerifyLaneCallback(0, sigs[1], msgs[1], pubKeys[1], func(valid bool, err error) {
+		results <- laneResult{index: 1, valid: valid, err: err}
+	}))
+	for range sigs {
+		select {
+		case result := <-results:
+			require.True(t, result.valid)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the fast lane missed its latency target")
+		}
+	}
+}
+
+func TestBatchVerifierOverload(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 3)
+	v := bls.NewPriorityBatchVerifier(100, []bls.LaneConfig{
+		{QueueSize: 2, Latency: time.Hour, Policy: bls.RejectWhenFull},
+		{QueueSize: 2, Latency: time.Hour, Policy: bls.DropOldestWhenFull},
+	})
+
+	results := make(chan laneResult, 6)
+	submit := func(lane bls.Lane, i int) error {
+		return v.VerifyLaneCallback(lane, sigs[i], msgs[i], pubKeys[i], func(valid bool, err error) {
+			results <- laneResult{index: int(lane)*10 + i, valid: valid, err: err}
+		})
+	}
+	require.NoError(t, submit(0, 0))
+	require.NoError(t, submit(0, 1))
+	require.ErrorIs(t, submit(0, 2), bls.ErrLaneFull)
+
+	require.NoError(t, submit(1, 0))
+	require.NoError(t, submit(1, 1))
+	require.NoError(t, submit(1, 2))
+	dropped := <-results
+	require.Equal(t, 10, dropped.index)
+	require.False(t, dropped.valid)
+	require.ErrorIs(t, dropped.err, bls.ErrSignatureDropped)
+
+	require.Error(t, submit(2, 0))
+
+	v.Close()
+	require.Len(t, results, 4)
+	for i := 0; i < 4; i++ {
+		result := <-results
+		require.True(t, result.valid)
+		require.NoError(t, result.err)
+	}
+}
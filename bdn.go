@@ -0,0 +1,97 @@
+package bls
+
+import (
+	"crypto/sha256"
+
+	"github.com/pkg/errors"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// Boneh-Drijvers-Neven multisignatures (https://eprint.iacr.org/2018/483.pdf) resist rogue key attacks
+// without proofs of possession: every key of the set is weighted by a coefficient t_i = H(pk_i, {pk_1..pk_n}),
+// so a key crafted after seeing the others can no longer cancel them out of the aggregate.
+// apk = t_1 * pk_1 + ... + t_n * pk_n
+// asig = t_1 * sig_1 + ... + t_n * sig_n
+
+// Coefficients are 128 bits long, as in the paper.
+const bdnCoefficientBits = 128
+
+var bdnCoefficientsDST = []byte("BLS_BDN_COEFFICIENTS_BLS12381_SHA-256_")
+
+// bdnCoefficients returns the coefficients of the set, packed little endian as expected by blst.
+// The set is hashed in the given order, signers and verifiers must agree on it.
+func bdnCoefficients(publicKeys [][]byte) []byte {
+	h := sha256.New()
+	h.Write(bdnCoefficientsDST)
+	for _, publicKey := range publicKeys {
+		h.Write(publicKey)
+	}
+	setDigest := h.Sum(nil)
+
+	coefficientBytes := bdnCoefficientBits / 8
+	coefficients := make([]byte, 0, len(publicKeys)*coefficientBytes)
+	for _, publicKey := range publicKeys {
+		h.Reset()
+		h.Write(bdnCoefficientsDST)
+		h.Write(setDigest)
+		h.Write(publicKey)
+		coefficients = append(coefficients, h.Sum(nil)[:coefficientBytes]...)
+	}
+	return coefficients
+}
+
+func bdnAggregatePublicKeys(pubs [][]byte) (*blst.P1Affine, error) {
+	if len(pubs) == 0 {
+		return nil, ErrNoPublicKeysToAggregate
+	}
+	mulP1 := make([]*blst.P1Affine, 0, len(pubs))
+	for _, pubkey := range pubs {
+		pubKeyObj, err := NewPublicKeyFromBytes(pubkey)
+		if err != nil {
+			return nil, err
+		}
+		mulP1 = append(mulP1, pubKeyObj)
+	}
+	return blst.P1AffinesMult(mulP1, bdnCoefficients(pubs), bdnCoefficientBits).ToAffine(), nil
+}
+
+// BDNAggregatePublicKeys aggregates a set of public keys, each weighted by its coefficient in the set.
+func BDNAggregatePublicKeys(pubs [][]byte) ([]byte, error) {
+	agg, err := bdnAggregatePublicKeys(pubs)
+	if err != nil {
+		return nil, err
+	}
+	return agg.Compress(), nil
+}
+
+// BDNAggregateSignatures aggregates the signatures of a set of public keys, sigs[i] must be signed by pubs[i].
+func BDNAggregateSignatures(pubs [][]byte, sigs [][]byte) ([]byte, error) {
+	if len(sigs) == 0 {
+		return nil, ErrNoSignaturesToAggregate
+	}
+	if len(sigs) != len(pubs) {
+		return nil, errors.Errorf("provided signatures and pubkeys have differing lengths. S: %d, P: %d", len(sigs), len(pubs))
+	}
+	mulP2 := make([]*blst.P2Affine, 0, len(sigs))
+	for _, sig := range sigs {
+		sigObj, err := NewSignatureFromBytes(sig)
+		if err != nil {
+			return nil, err
+		}
+		mulP2 = append(mulP2, sigObj.affine)
+	}
+	return blst.P2AffinesMult(mulP2, bdnCoefficients(pubs), bdnCoefficientBits).ToAffine().Compress(), nil
+}
+
+// VerifyBDN verify a BDN multisignature of msg against the set of public keys which produced it.
+func VerifyBDN(signature []byte, msg []byte, publicKeysBytes [][]byte) (bool, error) {
+	sig, err := NewSignatureFromBytes(signature)
+	if err != nil {
+		return false, err
+	}
+	apk, err := bdnAggregatePublicKeys(publicKeysBytes)
+	if err != nil {
+		return false, err
+	}
+	return sig.Verify(msg, apk), nil
+}
@@ -0,0 +1,81 @@
+package bls_test
+
+import (
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+func TestBDNMultisignature(t *testing.T) {
+	msg := convertHexToMessage("5656565656565656565656565656565656565656565656565656565656565656")
+	var publicKeys, sigs [][]byte
+	for i := 0; i < 4; i++ {
+		privateKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		publicKeys = append(publicKeys, bls.CompressPublicKey(privateKey.PublicKey()))
+		sigs = append(sigs, privateKey.Sign(msg).Bytes())
+	}
+	aggregatedSignature, err := bls.BDNAggregateSignatures(publicKeys, sigs)
+	require.NoError(t, err)
+
+	valid, err := bls.VerifyBDN(aggregatedSignature, msg, publicKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	// The aggregate public key goes through the ordinary verification path.
+	aggregatedPublicKey, err := bls.BDNAggregatePublicKeys(publicKeys)
+	require.NoError(t, err)
+	valid, err = bls.Verify(aggregatedSignature, msg, aggregatedPublicKey)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	// A plain aggregate is not a BDN multisignature.
+	plainSignature, err := bls.AggregateSignatures(sigs)
+	require.NoError(t, err)
+	valid, err = bls.VerifyBDN(plainSignature, msg, publicKeys)
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	// Coefficients depend on the whole set, so the signature does not verify for a subset.
+	valid, err = bls.VerifyBDN(aggregatedSignature, msg, publicKeys[1:])
+	require.NoError(t, err)
+	require.False(t, valid)
+}
+
+func TestBDNRogueKeyAttack(t *testing.T) {
+	msg := convertHexToMessage("abababababababababababababababababababababababababababababababab")
+	victim, err := bls.GenerateKey()
+	require.NoError(t, err)
+	attacker, err := bls.GenerateKey()
+	require.NoError(t, err)
+
+	// The rogue key is attacker - victim, which the attacker can't sign for but doesn't need to.
+	rogue := new(blst.P1)
+	rogue.FromAffine(attacker.PublicKey())
+	rogueKey := rogue.SubAssign((*blst.P1Affine)(victim.PublicKey())).ToAffine().Compress()
+	publicKeys := [][]byte{bls.CompressPublicKey(victim.PublicKey()), rogueKey}
+	forgery := attacker.Sign(msg).Bytes()
+
+	// Plain aggregation falls for it: victim + rogue = attacker.
+	aggregatedPublicKey, err := bls.AggregatePublickKeys(publicKeys)
+	require.NoError(t, err)
+	valid, err := bls.Verify(forgery, msg, aggregatedPublicKey)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	// BDN does not.
+	valid, err = bls.VerifyBDN(forgery, msg, publicKeys)
+	require.NoError(t, err)
+	require.False(t, valid)
+}
+
+func TestBDNAggregateErrors(t *testing.T) {
+	_, err := bls.BDNAggregatePublicKeys(nil)
+	require.ErrorIs(t, err, bls.ErrNoPublicKeysToAggregate)
+	_, err = bls.BDNAggregateSignatures(nil, nil)
+	require.ErrorIs(t, err, bls.ErrNoSignaturesToAggregate)
+	_, err = bls.BDNAggregateSignatures([][]byte{}, [][]byte{bls.InfiniteSignature[:]})
+	require.Error(t, err)
+}
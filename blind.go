@@ -0,0 +1,99 @@
+package bls
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// Blind signatures: the requester sends r * H(m) to the signer, gets back sk * r * H(m) and
+// multiplies it by 1/r to obtain sk * H(m), an ordinary signature of m. Since r is uniformly random,
+// r * H(m) is a uniformly random point of G2 whatever m is, so the signer learns nothing about m.
+
+// BlindingFactor is the secret a requester uses to blind a message and unblind its signature.
+type BlindingFactor struct {
+	r *blst.Scalar
+}
+
+// BlindedMessage is a hashed message hidden by a blinding factor, it is what the signer sees.
+type BlindedMessage struct {
+	affine *blst.P2Affine
+}
+
+// BlindedSignature is the signature of a blinded message, only the requester can unblind it.
+type BlindedSignature struct {
+	affine *blst.P2Affine
+}
+
+// randomScalar returns a uniformly random non zero scalar.
+func randomScalar() (*blst.Scalar, error) {
+	// Reducing 64 bytes modulo the group order leaves a negligible bias.
+	var rbytes [2 * scalarBytes]byte
+	for {
+		if _, err := rand.Read(rbytes[:]); err != nil {
+			return nil, err
+		}
+		scalar := new(blst.Scalar).FromBEndian(rbytes[:])
+		if scalar != nil && scalar.Valid() {
+			return scalar, nil
+		}
+	}
+}
+
+// Blind hashes msg and hides it behind a fresh blinding factor.
+func Blind(msg []byte) (*BlindedMessage, *BlindingFactor, error) {
+	r, err := randomScalar()
+	if err != nil {
+		return nil, nil, err
+	}
+	blinded := blst.HashToG2(msg, eth2Curve).MultAssign(r).ToAffine()
+	return &BlindedMessage{affine: blinded}, &BlindingFactor{r: r}, nil
+}
+
+// NewBlindedMessageFromBytes creates a blinded message from a 96 bytes long slice.
+func NewBlindedMessageFromBytes(b []byte) (*BlindedMessage, error) {
+	if len(b) != signatureLength {
+		return nil, fmt.Errorf("bls(blind): invalid blinded message length. should be %d", signatureLength)
+	}
+	blinded := new(blst.P2Affine).Uncompress(b)
+	if blinded == nil {
+		return nil, ErrDeserializeSignature
+	}
+	// The signer must never multiply a point outside of the group, nor infinity, by its key.
+	if !blinded.SigValidate(true) {
+		return nil, ErrNotGroupSignature
+	}
+	return &BlindedMessage{affine: blinded}, nil
+}
+
+func (b *BlindedMessage) Bytes() []byte {
+	return b.affine.Compress()
+}
+
+// NewBlindedSignatureFromBytes creates a blinded signature from a 96 bytes long slice.
+func NewBlindedSignatureFromBytes(b []byte) (*BlindedSignature, error) {
+	signature, err := NewSignatureFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	return &BlindedSignature{affine: signature.affine}, nil
+}
+
+func (s *BlindedSignature) Bytes() []byte {
+	return s.affine.Compress()
+}
+
+// SignBlinded signs a blinded message without learning the message.
+func (p *PrivateKey) SignBlinded(b *BlindedMessage) *BlindedSignature {
+	point := new(blst.P2)
+	point.FromAffine(b.affine)
+	return &BlindedSignature{affine: point.MultAssign(p.key).ToAffine()}
+}
+
+// Unblind turns the signature of a blinded message into an ordinary signature of the message.
+func (f *BlindingFactor) Unblind(s *BlindedSignature) *Signature {
+	point := new(blst.P2)
+	point.FromAffine(s.affine)
+	return &Signature{affine: point.MultAssign(f.r.Inverse()).ToAffine()}
+}
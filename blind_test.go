@@ -0,0 +1,84 @@
+package bls_test
+
+import (
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlindSignature(t *testing.T) {
+	privateKey, err := bls.NewPrivateKeyFromBytes(convertHexToPrivateKey("47b8192d77bf871b62e87859d653922725724a5c031afeabc60bcef5ff665138"))
+	require.NoError(t, err)
+	msg := convertHexToMessage("5656565656565656565656565656565656565656565656565656565656565656")
+
+	blinded, factor, err := bls.Blind(msg)
+	require.NoError(t, err)
+	// The blinded message goes over the wire to the signer.
+	received, err := bls.NewBlindedMessageFromBytes(blinded.Bytes())
+	require.NoError(t, err)
+	blindedSignature, err := bls.NewBlindedSignatureFromBytes(privateKey.SignBlinded(received).Bytes())
+	require.NoError(t, err)
+
+	signature := factor.Unblind(blindedSignature)
+	// The result is the ordinary, deterministic signature of msg.
+	require.Equal(t, convertHexToSignature("af1390c3c47acdb37131a51216da683c509fce0e954328a59f93aebda7e4ff974ba208d9a4a2a2389f892a9d418d618418dd7f7a6bc7aa0da999a9d3a5b815bc085e14fd001f6a1948768a3f4afefc8b8240dda329f984cb345c6363272ba4fe"), signature.Bytes())
+	valid, err := bls.Verify(signature.Bytes(), msg, bls.CompressPublicKey(privateKey.PublicKey()))
+	require.NoError(t, err)
+	require.True(t, valid)
+}
+
+func TestBlindSignerViewIndependentOfMessage(t *testing.T) {
+	privateKey, err := bls.GenerateKey()
+	require.NoError(t, err)
+	msg := convertHexToMessage("5656565656565656565656565656565656565656565656565656565656565656")
+	otherMsg := convertHexToMessage("abababababababababababababababababababababababababababababababab")
+
+	// Everything the signer sees over several sessions.
+	seen := map[string]struct{}{}
+	signatures := map[string]struct{}{}
+	for i := 0; i < 8; i++ {
+		for _, m := range [][]byte{msg, otherMsg} {
+			blinded, factor, err := bls.Blind(m)
+			require.NoError(t, err)
+			blindedSignature := privateKey.SignBlinded(blinded)
+
+			// Blinding the same message twice never gives the same view, so sessions can't be linked by message.
+			_, ok := seen[string(blinded.Bytes())]
+			require.False(t, ok)
+			seen[string(blinded.Bytes())] = struct{}{}
+			_, ok = seen[string(blindedSignature.Bytes())]
+			require.False(t, ok)
+			seen[string(blindedSignature.Bytes())] = struct{}{}
+
+			signature := factor.Unblind(blindedSignature)
+			require.True(t, signature.Verify(m, privateKey.PublicKey()))
+			signatures[string(signature.Bytes())] = struct{}{}
+		}
+	}
+	// Whereas the unblinded signatures only depend on the message.
+	require.Len(t, signatures, 2)
+	// None of the plain signatures is in the signer's view.
+	for signature := range signatures {
+		_, ok := seen[signature]
+		require.False(t, ok)
+	}
+}
+
+func TestBlindWrongFactor(t *testing.T) {
+	privateKey, err := bls.GenerateKey()
+	require.NoError(t, err)
+	msg := convertHexToMessage("5656565656565656565656565656565656565656565656565656565656565656")
+
+	blinded, _, err := bls.Blind(msg)
+	require.NoError(t, err)
+	_, otherFactor, err := bls.Blind(msg)
+	require.NoError(t, err)
+	signature := otherFactor.Unblind(privateKey.SignBlinded(blinded))
+	require.False(t, signature.Verify(msg, privateKey.PublicKey()))
+}
+
+func TestBlindedMessageInfinity(t *testing.T) {
+	_, err := bls.NewBlindedMessageFromBytes(bls.InfiniteSignature[:])
+	require.ErrorIs(t, err, bls.ErrNotGroupSignature)
+}
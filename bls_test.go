@@ -6,6 +6,7 @@ import (
 
 	"github.com/Giulio2002/bls"
 	"github.com/stretchr/testify/require"
+	blst "github.com/supranational/blst/bindings/go"
 )
 
 func convertHexToPublicKey(h string) []byte {
@@ -38,6 +39,17 @@ func convertHexToPrivateKey(h string) []byte {
 	return pk
 }
 
+// referenceSign computes sk * hash_to_curve(msg) in G2 under dst straight from the curve primitives, so that
+// vectors are checked against the definition of the scheme and not only against bls.
+func referenceSign(privateKey []byte, msg []byte, dst []byte) []byte {
+	return blst.HashToG2(msg, dst).Mult(new(blst.Scalar).Deserialize(privateKey)).ToAffine().Compress()
+}
+
+// referencePublicKey computes sk * G1 straight from the curve primitives.
+func referencePublicKey(privateKey []byte) []byte {
+	return blst.P1Generator().Mult(new(blst.Scalar).Deserialize(privateKey)).ToAffine().Compress()
+}
+
 func convertHexToMessage(h string) []byte {
 	b, err := hex.DecodeString(h)
 	if err != nil {
@@ -0,0 +1,164 @@
+package bls
+
+import (
+	"sync"
+	"time"
+)
+
+// Relative costs of the operations of a verification, a pairing being a Miller loop with its share of the final
+// exponentiation. Decompressing a point includes its subgroup check.
+const (
+	pairingCost       = 4
+	decompressionCost = 1
+)
+
+// VerificationCost is the estimated work of a verification.
+type VerificationCost struct {
+	Pairings       int
+	Decompressions int
+}
+
+// Units is the cost charged to a budget.
+func (c VerificationCost) Units() float64 {
+	return float64(c.Pairings*pairingCost + c.Decompressions*decompressionCost)
+}
+
+// VerifyCost is the cost of Verify.
+func VerifyCost() VerificationCost {
+	return VerificationCost{Pairings: 2, Decompressions: 2}
+}
+
+// VerifyAggregateCost is the cost of VerifyAggregate with n public keys.
+func VerifyAggregateCost(n int) VerificationCost {
+	return VerificationCost{Pairings: 2, Decompressions: 1 + n}
+}
+
+// AggregateVerifyCost is the cost of AggregateVerify with n messages.
+func AggregateVerifyCost(n int) VerificationCost {
+	return VerificationCost{Pairings: n + 1, Decompressions: 1 + n}
+}
+
+// VerifyMultipleSignaturesCost is the cost of VerifyMultipleSignatures with n signatures.
+func VerifyMultipleSignaturesCost(n int) VerificationCost {
+	return VerificationCost{Pairings: n + 1, Decompressions: 2 * n}
+}
+
+// bucket is the budget of a source, refilled from last to now when charged.
+type bucket struct {
+	units float64
+	last  time.Time
+}
+
+// VerificationBudget limits the verification work done on behalf of each source, such as a peer, so that a
+// source sending invalid signatures cannot use up the CPU. Every source has a budget of capacity units, refilled
+// at refill units per second, and work over budget is rejected with ErrBudgetExceeded without being computed.
+// It is safe for concurrent use.
+type VerificationBudget struct {
+	capacity float64
+	refill   float64
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	// pruneAt is the number of sources above which full budgets are forgotten.
+	pruneAt int
+}
+
+// NewVerificationBudget creates a budget of capacity units per source, refilled at refill units per second.
+// Verify costs 10 units, see VerificationCost.
+func NewVerificationBudget(capacity float64, refill float64) *VerificationBudget {
+	return &VerificationBudget{
+		capacity: capacity,
+		refill:   refill,
+		buckets:  make(map[string]*bucket),
+		pruneAt:  1024,
+	}
+}
+
+// Charge takes cost from the budget of source, or returns ErrBudgetExceeded and takes nothing if it is not
+// enough. Callers doing their own verifications, e.g. through a BatchVerifier, charge them here and may submit
+// the rejected ones to a lower priority lane instead of dropping them.
+func (b *VerificationBudget) Charge(source string, cost VerificationCost) error {
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	bk := b.bucket(source, now)
+	units := cost.Units()
+	if bk.units < units {
+		return ErrBudgetExceeded
+	}
+	bk.units -= units
+	return nil
+}
+
+// Remaining returns the units left in the budget of source.
+func (b *VerificationBudget) Remaining(source string) float64 {
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.bucket(source, now).units
+}
+
+// Forget drops the budget of source, e.g. once the peer disconnected.
+func (b *VerificationBudget) Forget(source string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.buckets, source)
+}
+
+// bucket returns the refilled budget of source, new sources start full.
+func (b *VerificationBudget) bucket(source string, now time.Time) *bucket {
+	bk, ok := b.buckets[source]
+	if !ok {
+		if len(b.buckets) >= b.pruneAt {
+			b.prune(now)
+		}
+		bk = &bucket{units: b.capacity, last: now}
+		b.buckets[source] = bk
+		return bk
+	}
+	bk.units = min(b.capacity, bk.units+now.Sub(bk.last).Seconds()*b.refill)
+	bk.last = now
+	return bk
+}
+
+// prune forgets the sources whose budget refilled, they are the same as new ones.
+func (b *VerificationBudget) prune(now time.Time) {
+	for source, bk := range b.buckets {
+		if bk.units+now.Sub(bk.last).Seconds()*b.refill >= b.capacity {
+			delete(b.buckets, source)
+		}
+	}
+	b.pruneAt = max(1024, 2*len(b.buckets))
+}
+
+// Verify is Verify charged to source.
+func (b *VerificationBudget) Verify(source string, signature []byte, msg []byte, publicKey []byte) (bool, error) {
+	if err := b.Charge(source, VerifyCost()); err != nil {
+		return false, err
+	}
+	return Verify(signature, msg, publicKey)
+}
+
+// VerifyAggregate is VerifyAggregate charged to source.
+func (b *VerificationBudget) VerifyAggregate(source string, signature []byte, msg []byte, publicKeys [][]byte) (bool, error) {
+	if err := b.Charge(source, VerifyAggregateCost(len(publicKeys))); err != nil {
+		return false, err
+	}
+	return VerifyAggregate(signature, msg, publicKeys)
+}
+
+// AggregateVerify is AggregateVerify charged to source.
+func (b *VerificationBudget) AggregateVerify(source string, signature []byte, msgs [][]byte, publicKeys [][]byte, enforceDistinct bool) (bool, error) {
+	if err := b.Charge(source, AggregateVerifyCost(len(msgs))); err != nil {
+		return false, err
+	}
+	return AggregateVerify(signature, msgs, publicKeys, enforceDistinct)
+}
+
+// VerifyMultipleSignatures is VerifyMultipleSignatures charged to source.
+func (b *VerificationBudget) VerifyMultipleSignatures(source string, sigs [][]byte, msgs [][]byte, pubKeys [][]byte) (bool, error) {
+	if err := b.Charge(source, VerifyMultipleSignaturesCost(len(sigs))); err != nil {
+		return false, err
+	}
+	return VerifyMultipleSignatures(sigs, msgs, pubKeys)
+}
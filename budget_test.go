@@ -0,0 +1,52 @@
+package bls_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationBudget(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 1)
+	verifyUnits := bls.VerifyCost().Units()
+	budget := bls.NewVerificationBudget(2*verifyUnits, 0)
+
+	for i := 0; i < 2; i++ {
+		valid, err := budget.Verify("peer", sigs[0], []byte("tampered"), pubKeys[0])
+		require.NoError(t, err)
+		require.False(t, valid)
+	}
+	require.Zero(t, budget.Remaining("peer"))
+	_, err := budget.Verify("peer", sigs[0], msgs[0], pubKeys[0])
+	require.ErrorIs(t, err, bls.ErrBudgetExceeded)
+
+	// Other sources have their own budget.
+	valid, err := budget.Verify("other", sigs[0], msgs[0], pubKeys[0])
+	require.NoError(t, err)
+	require.True(t, valid)
+	_, err = budget.VerifyAggregate("other", sigs[0], msgs[0], [][]byte{pubKeys[0], pubKeys[0]})
+	require.ErrorIs(t, err, bls.ErrBudgetExceeded)
+	require.Equal(t, verifyUnits, budget.Remaining("other"))
+
+	budget.Forget("peer")
+	require.Equal(t, 2*verifyUnits, budget.Remaining("peer"))
+}
+
+func TestVerificationBudgetRefill(t *testing.T) {
+	cost := bls.VerifyMultipleSignaturesCost(4)
+	budget := bls.NewVerificationBudget(cost.Units(), 1000*cost.Units())
+	require.NoError(t, budget.Charge("peer", cost))
+	require.Less(t, budget.Remaining("peer"), cost.Units())
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, cost.Units(), budget.Remaining("peer"))
+	require.NoError(t, budget.Charge("peer", cost))
+}
+
+func TestVerificationCosts(t *testing.T) {
+	require.Equal(t, bls.VerificationCost{Pairings: 2, Decompressions: 2}, bls.VerifyCost())
+	require.Greater(t, bls.VerifyAggregateCost(64).Units(), bls.VerifyAggregateCost(1).Units())
+	require.Greater(t, bls.AggregateVerifyCost(4).Units(), bls.VerifyAggregateCost(4).Units())
+	require.Less(t, bls.VerifyMultipleSignaturesCost(8).Units(), 8*bls.VerifyCost().Units())
+}
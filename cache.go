@@ -9,18 +9,20 @@ import (
 )
 
 var (
-	pkCache      *publicKeysCache
-	enabledCache bool
+	pkCache       *publicKeysCache[blst.P1Affine]
+	minSigPkCache *publicKeysCache[blst.P2Affine]
+	enabledCache  bool
 )
 
 // We build a basic cache to avoid allocs with sync.Map.
-type kvCache struct {
+type kvCache[T any] struct {
 	key   []byte
-	value *blst.P1Affine
+	value *T
 }
 
-type publicKeysCache struct {
-	cache [][]kvCache
+type publicKeysCache[T any] struct {
+	cache     [][]kvCache[T]
+	keyLength int
 
 	mu sync.RWMutex
 }
@@ -29,8 +31,10 @@ const baseCacheLayer = 16384
 
 // init is used to initialize cache
 func init() {
-	pkCache = &publicKeysCache{}
-	pkCache.cache = make([][]kvCache, baseCacheLayer)
+	pkCache = &publicKeysCache[blst.P1Affine]{keyLength: publicKeyLength}
+	pkCache.cache = make([][]kvCache[blst.P1Affine], baseCacheLayer)
+	minSigPkCache = &publicKeysCache[blst.P2Affine]{keyLength: minSigPublicKeyLength}
+	minSigPkCache.cache = make([][]kvCache[blst.P2Affine], baseCacheLayer)
 }
 
 func SetEnabledCaching(caching bool) {
@@ -38,26 +42,53 @@ func SetEnabledCaching(caching bool) {
 }
 
 func ClearCache() {
-	pkCache.cache = make([][]kvCache, baseCacheLayer)
+	pkCache.clear()
+	minSigPkCache.clear()
 }
 
-func (p *publicKeysCache) loadPublicKeyIntoCache(publicKey []byte, validate bool) error {
+func (p *publicKeysCache[T]) clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cache = make([][]kvCache[T], baseCacheLayer)
+}
+
+func loadPublicKeyIntoCache(publicKey []byte, validate bool) error {
 	if len(publicKey) != publicKeyLength {
 		return ErrDeserializePublicKey
 	}
-	if affine := p.getAffineFromCache(publicKey); affine != nil {
+	if affine := pkCache.getAffineFromCache(publicKey); affine != nil {
 		return nil
 	}
 	// Subgroup check NOT done when decompressing pubkey.
 	publicKeyDecompressed := new(blst.P1Affine).Uncompress(publicKey)
-	if p == nil {
+	if publicKeyDecompressed == nil {
 		return ErrDeserializePublicKey
 	}
 	// Subgroup and infinity check
 	if validate && !publicKeyDecompressed.KeyValidate() {
 		return ErrInfinitePublicKey
 	}
-	p.loadAffineIntoCache(publicKey, publicKeyDecompressed)
+	pkCache.loadAffineIntoCache(publicKey, publicKeyDecompressed)
+	return nil
+}
+
+func loadMinSigPublicKeyIntoCache(publicKey []byte, validate bool) error {
+	if len(publicKey) != minSigPublicKeyLength {
+		return ErrDeserializePublicKey
+	}
+	if affine := minSigPkCache.getAffineFromCache(publicKey); affine != nil {
+		return nil
+	}
+	// Subgroup check NOT done when decompressing pubkey.
+	publicKeyDecompressed := new(blst.P2Affine).Uncompress(publicKey)
+	if publicKeyDecompressed == nil {
+		return ErrDeserializePublicKey
+	}
+	// Subgroup and infinity check
+	if validate && !publicKeyDecompressed.KeyValidate() {
+		return ErrInfinitePublicKey
+	}
+	minSigPkCache.loadAffineIntoCache(publicKey, publicKeyDecompressed)
 	return nil
 }
 
@@ -67,18 +98,18 @@ func copyBytes(b []byte) []byte {
 	return c
 }
 
-func (p *publicKeysCache) loadAffineIntoCache(key []byte, affine *blst.P1Affine) {
+func (p *publicKeysCache[T]) loadAffineIntoCache(key []byte, affine *T) {
 	if !enabledCache {
 		return
 	}
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	var idx int
-	for i := 0; i < publicKeyLength; i++ {
+	for i := 0; i < p.keyLength; i++ {
 		idx += int(key[i])
 	}
 	baseIdx := idx % baseCacheLayer
-	p.cache[baseIdx] = append(p.cache[baseIdx], kvCache{key: copyBytes(key), value: affine})
+	p.cache[baseIdx] = append(p.cache[baseIdx], kvCache[T]{key: copyBytes(key), value: affine})
 	sort.Slice(p.cache[baseIdx], func(i, j int) bool {
 		return bytes.Compare(p.cache[baseIdx][i].key, p.cache[baseIdx][j].key) < 0
 	})
@@ -88,20 +119,28 @@ func LoadPublicKeyIntoCache(publicKey []byte, validate bool) error {
 	if !enabledCache {
 		return ErrCacheNotEnabled
 	}
-	return pkCache.loadPublicKeyIntoCache(publicKey, validate)
+	return loadPublicKeyIntoCache(publicKey, validate)
+}
+
+// LoadMinSigPublicKeyIntoCache is LoadPublicKeyIntoCache for the 96 bytes long public keys of the min-sig variant.
+func LoadMinSigPublicKeyIntoCache(publicKey []byte, validate bool) error {
+	if !enabledCache {
+		return ErrCacheNotEnabled
+	}
+	return loadMinSigPublicKeyIntoCache(publicKey, validate)
 }
 
-func (p *publicKeysCache) getAffineFromCache(key []byte) *blst.P1Affine {
+func (p *publicKeysCache[T]) getAffineFromCache(key []byte) *T {
 	if !enabledCache {
 		return nil
 	}
-	if len(key) != publicKeyLength {
+	if len(key) != p.keyLength {
 		return nil
 	}
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 	var idx int
-	for i := 0; i < publicKeyLength; i++ {
+	for i := 0; i < p.keyLength; i++ {
 		idx += int(key[i])
 	}
 
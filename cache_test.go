@@ -1,6 +1,7 @@
 package bls_test
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/Giulio2002/bls"
@@ -27,6 +28,33 @@ func TestAggregateSignatureCached(t *testing.T) {
 	bls.SetEnabledCaching(false)
 }
 
+func TestLoadPublicKeyIntoCacheUndecodable(t *testing.T) {
+	bls.SetEnabledCaching(true)
+	defer bls.SetEnabledCaching(false)
+	// Not the x coordinate of a point on the curve.
+	publicKey := convertHexToPublicKey("a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79b")
+	require.ErrorIs(t, bls.LoadPublicKeyIntoCache(publicKey, false), bls.ErrDeserializePublicKey)
+	require.ErrorIs(t, bls.LoadPublicKeyIntoCache(publicKey, true), bls.ErrDeserializePublicKey)
+}
+
+func TestClearCacheConcurrently(t *testing.T) {
+	bls.SetEnabledCaching(true)
+	defer bls.SetEnabledCaching(false)
+	publicKey := convertHexToPublicKey("a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a")
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				require.NoError(t, bls.LoadPublicKeyIntoCache(publicKey, true))
+				bls.ClearCache()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 /*
 BenchmarkAggregateSigCached-12    	    1779	    669063 ns/op	    6834 B/op	      47 allocs/op
 PASS
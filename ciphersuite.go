@@ -0,0 +1,241 @@
+package bls
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/pkg/errors"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// scheme defines how a ciphersuite protects itself against rogue key attacks.
+type scheme int
+
+const (
+	// schemeBasic requires the messages of an aggregate to be distinct.
+	schemeBasic scheme = iota
+	// schemeAug prefixes every message with the public key of its signer.
+	schemeAug
+	// schemePop relies on proofs of possession having been checked for every public key.
+	schemePop
+)
+
+// Ciphersuite carries the DST and the rules of one of the IETF BLS signature schemes.
+type Ciphersuite struct {
+	dst []byte
+	// minSigDST is used by the min-sig variant, which hashes messages to G1.
+	minSigDST []byte
+	scheme    scheme
+}
+
+var (
+	// BasicCiphersuite is the Basic (NUL) scheme, as used by Filecoin.
+	BasicCiphersuite = &Ciphersuite{
+		dst:       []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_NUL_"),
+		minSigDST: []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_"),
+		scheme:    schemeBasic,
+	}
+	// AugCiphersuite is the Message Augmentation (AUG) scheme, as used by Chia.
+	AugCiphersuite = &Ciphersuite{
+		dst:       []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_AUG_"),
+		minSigDST: []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_AUG_"),
+		scheme:    schemeAug,
+	}
+	// PopCiphersuite is the Proof of Possession (POP) scheme, as used by Ethereum 2.0.
+	PopCiphersuite = &Ciphersuite{
+		dst:       eth2Curve,
+		minSigDST: []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_POP_"),
+		scheme:    schemePop,
+	}
+)
+
+// augmentation returns the prefix of the message signed by publicKey.
+func (c *Ciphersuite) augmentation(publicKey PublicKey) []byte {
+	if c.scheme != schemeAug {
+		return nil
+	}
+	return CompressPublicKey(publicKey)
+}
+
+// Sign a message with BLS.
+func (c *Ciphersuite) Sign(p *PrivateKey, msg []byte) *Signature {
+	signature := new(blst.P2Affine).Sign(p.key, msg, c.dst, c.augmentation(p.PublicKey()))
+	return &Signature{affine: signature}
+}
+
+// VerifySignature verify signature against one public key.
+func (c *Ciphersuite) VerifySignature(s *Signature, msg []byte, pk PublicKey) bool {
+	return s.affine.Verify(false, pk, false, msg, c.dst, c.augmentation(pk))
+}
+
+// VerifyAggregateSignature verify signature against many public keys which signed the same message.
+func (c *Ciphersuite) VerifyAggregateSignature(s *Signature, msg []byte, publicKeys []PublicKey) (bool, error) {
+	affines := make([]*blst.P1Affine, 0, len(publicKeys))
+	for _, publicKey := range publicKeys {
+		affines = append(affines, publicKey)
+	}
+	switch c.scheme {
+	case schemeBasic:
+		// The same message signed twice is not distinct.
+		if len(publicKeys) > 1 {
+			return false, ErrMessagesNotDistinct
+		}
+		msgs := make([]blst.Message, len(affines))
+		for i := range msgs {
+			msgs[i] = msg
+		}
+		return s.affine.AggregateVerify(true, affines, false, msgs, c.dst), nil
+	case schemeAug:
+		msgs := make([]blst.Message, len(affines))
+		augs := make([][]byte, len(affines))
+		for i, publicKey := range publicKeys {
+			msgs[i] = msg
+			augs[i] = c.augmentation(publicKey)
+		}
+		return s.affine.AggregateVerify(true, affines, false, msgs, c.dst, augs), nil
+	default:
+		return s.affine.FastAggregateVerify(true, affines, msg, c.dst), nil
+	}
+}
+
+// AggregateVerifySignature verify signature against many public keys, each of which signed its own message.
+// Basic always requires the messages to be distinct, the other schemes only when enforceDistinct is set.
+func (c *Ciphersuite) AggregateVerifySignature(s *Signature, msgs [][]byte, publicKeys []PublicKey, enforceDistinct bool) (bool, error) {
+	if len(msgs) != len(publicKeys) {
+		return false, errors.Errorf("provided messages and pubkeys have differing lengths. M: %d, P: %d", len(msgs), len(publicKeys))
+	}
+	if (enforceDistinct || c.scheme == schemeBasic) && !distinctMessages(msgs) {
+		return false, ErrMessagesNotDistinct
+	}
+	affines := make([]*blst.P1Affine, len(publicKeys))
+	rawMsgs := make([]blst.Message, len(msgs))
+	for i, publicKey := range publicKeys {
+		affines[i] = publicKey
+		rawMsgs[i] = msgs[i]
+	}
+	if c.scheme == schemeAug {
+		augs := make([][]byte, len(publicKeys))
+		for i, publicKey := range publicKeys {
+			augs[i] = c.augmentation(publicKey)
+		}
+		return s.affine.AggregateVerify(true, affines, false, rawMsgs, c.dst, augs), nil
+	}
+	return s.affine.AggregateVerify(true, affines, false, rawMsgs, c.dst), nil
+}
+
+// Verify verify signature against one public key.
+func (c *Ciphersuite) Verify(signature []byte, msg []byte, publicKeyBytes []byte) (bool, error) {
+	return c.verifyContext(context.Background(), rawSignature(signature), msg, publicKeyBytes)
+}
+
+// verifyContext is Verify, abandoned once ctx is done.
+func (c *Ciphersuite) verifyContext(ctx context.Context, signature SignatureSource, msg []byte, publicKeyBytes []byte) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	key, cached := verifications.lookup(c.dst, signature.Bytes(), msg, publicKeyBytes)
+	if cached {
+		return true, nil
+	}
+	sig, err := signature.Signature()
+	if err != nil {
+		return false, err
+	}
+
+	publicKey, err := NewPublicKeyFromBytes(publicKeyBytes)
+	if err != nil {
+		return false, err
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	if !c.VerifySignature(sig, msg, publicKey) {
+		return false, nil
+	}
+	verifications.add(key)
+	return true, nil
+}
+
+// VerifyAggregate verify signature against many public keys.
+func (c *Ciphersuite) VerifyAggregate(signature []byte, msg []byte, publicKeysBytes [][]byte) (bool, error) {
+	return c.verifyAggregateContext(context.Background(), rawSignature(signature), msg, publicKeysBytes)
+}
+
+// verifyAggregateContext is VerifyAggregate, abandoned once ctx is done. The keys are subgroup checked at once.
+func (c *Ciphersuite) verifyAggregateContext(ctx context.Context, signature SignatureSource, msg []byte, publicKeysBytes [][]byte) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	encoded := signature.Bytes()
+	if c.scheme == schemePop && len(publicKeysBytes) == 0 && bytes.Equal(InfiniteSignature[:], encoded) {
+		return true, nil
+	}
+	cacheKey, cached := verifications.lookup(c.dst, encoded, msg, publicKeysBytes...)
+	if cached {
+		return true, nil
+	}
+	sig, err := signature.Signature()
+	if err != nil {
+		return false, err
+	}
+
+	publicKeys, err := decodePublicKeysContext(ctx, publicKeysBytes, true)
+	if err != nil {
+		return false, err
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	valid, err := c.VerifyAggregateSignature(sig, msg, publicKeys)
+	if valid {
+		verifications.add(cacheKey)
+	}
+	return valid, err
+}
+
+// AggregateVerify verify signature against many public keys, each of which signed its own message.
+func (c *Ciphersuite) AggregateVerify(signature []byte, msgs [][]byte, publicKeysBytes [][]byte, enforceDistinct bool) (bool, error) {
+	return c.aggregateVerify(rawSignature(signature), msgs, publicKeysBytes, enforceDistinct)
+}
+
+func (c *Ciphersuite) aggregateVerify(signature SignatureSource, msgs [][]byte, publicKeysBytes [][]byte, enforceDistinct bool) (bool, error) {
+	sig, err := signature.Signature()
+	if err != nil {
+		return false, err
+	}
+
+	publicKeys := []PublicKey{}
+	for _, publicKey := range publicKeysBytes {
+		key, err := NewPublicKeyFromBytes(publicKey)
+		if err != nil {
+			return false, err
+		}
+		publicKeys = append(publicKeys, key)
+	}
+
+	return c.AggregateVerifySignature(sig, msgs, publicKeys, enforceDistinct)
+}
+
+// VerifyMultipleSignatures verifies a non-singular set of signatures and its respective pubkeys and messages.
+// Each signature is checked on its own, so Basic does not require the messages to be distinct here.
+func (c *Ciphersuite) VerifyMultipleSignatures(sigs [][]byte, msgs [][]byte, pubKeys [][]byte) (bool, error) {
+	var augs [][]byte
+	if c.scheme == schemeAug {
+		augs = pubKeys
+	}
+	return verifyMultipleSignatures(sigs, msgs, pubKeys, c.dst, augs)
+}
+
+// distinctMessages checks that no message appears twice.
+func distinctMessages(msgs [][]byte) bool {
+	seen := make(map[string]struct{}, len(msgs))
+	for _, msg := range msgs {
+		if _, ok := seen[string(msg)]; ok {
+			return false
+		}
+		seen[string(msg)] = struct{}{}
+	}
+	return true
+}
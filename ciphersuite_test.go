@@ -0,0 +1,179 @@
+package bls_test
+
+import (
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+var (
+	ciphersuitePrivateKeys = []string{
+		"263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3",
+		"47b8192d77bf871b62e87859d653922725724a5c031afeabc60bcef5ff665138",
+		"328388aff0d4a5b7dc9205abd374e7e98f3cd9f3418edb4eafda5fb16473d216",
+	}
+	ciphersuitePublicKeys = []string{
+		"a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a",
+		"b301803f8b5ac4a1133581fc676dfedc60d891dd5fa99028805e5ea5b08d3491af75d0707adab3b70c6a6a580217bf81",
+		"b53d21a4cfd562c469cc81514d4ce5a6b577d8403d32a394dc265dd190b47fa9f829fdd7963afdf972e5e77854051f6f",
+	}
+	ciphersuiteMessages = []string{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"5656565656565656565656565656565656565656565656565656565656565656",
+		"abababababababababababababababababababababababababababababababab",
+	}
+)
+
+// Signatures of ciphersuiteMessages[i] by ciphersuitePrivateKeys[i] for each suite. The POP signatures are the
+// consensus-spec sign vectors. The Basic and AUG values are not the IETF draft vectors: they were computed with
+// github.com/kilic/bls12-381, an implementation independent of blst, following draft-irtf-cfrg-bls-signature-05
+// with the DSTs of section 4.2, and TestCiphersuiteReference recomputes all of them from that definition.
+var ciphersuiteTestVectors = []struct {
+	name        string
+	ciphersuite *bls.Ciphersuite
+	signatures  []string
+	// Aggregate of all the signatures of ciphersuiteMessages[1].
+	sameMessageAggregate string
+}{
+	{
+		name:        "basic",
+		ciphersuite: bls.BasicCiphersuite,
+		signatures: []string{
+			"b9557b35d90f5c26ecfd841f17f97d107e66bd21311ba1ccee60b9741541435cdc1c665010ef60f4d351613478f0beca0c93d82504642f31bde38cadc02098931bb4b3d494d46c8ead659a64004ddb7c5c062c5c3cb09f33038d8818d9ce67f1",
+			"a13ca0662e900a7ae70b9e0d83a6c80d6ab215f9bf007c38940238fb2456f9cdbf7087f348b35dbde3433e9955d1eac30d7462b428437605646483b69acfc2eac8ec45bb48534d4a7438053245eccb7a32e4315feb63818a68a468fd3dce4c3e",
+			"8e379ea266aa302b69b1450b6f7da8144eada3496d9c6b383c648fe9ca0d9705347adcbc6dbc4455c0d20ad43bf07ac801a06fadb6389280a570ba68982b77de37a2a7f938978fa4bb1af9ba8d08b3a3cdd30f0485b304ba2360da10c5b1cfa9",
+		},
+		sameMessageAggregate: "8dc66b9428f6ad1f0b146c9d76a81705ccad18eccd450e07bf123b984510b7694196c2a1e4f89edd4055aeacebd1da0a152cc746afbcb73661b0c6be35ad42f89587e8dee2e9ef992f86fe3f2535ee62b49a172e321375f466d82715d824e7cb",
+	},
+	{
+		name:        "aug",
+		ciphersuite: bls.AugCiphersuite,
+		signatures: []string{
+			"80d0337c25b515decfe00d3e801abab5720922159b3eae42260a55fcb6db52216ef7165443bb7778e75f5876e297616f09ae288b75673e5a8f96bb50b0d73211badc15c07da8ff2a2026f400209c2f387e6a849ca7ba175c18e6b5edd3db757c",
+			"991e710684ff3751a73c8ada7ff2978688f691c6fb7eea740e12814707423fb1c1224345dbffa1fde7ad05798195f5af10e850152e3ef8e2d2515eae9cda346e96c968580b94531e27afe824cec6a99917b20ca80273fcb9c88f80a0f8daa242",
+			"85c909a3d90ef5f5dd37b8d978e342cc6c9ca110e3b7287d40081dda75a7889dc85fc05d120c7cbd055c09f3f7cee8050965edb1ea11ed436140078c8eae67bb8eb45d414d9642700f1907b25739603c4f3638e6c41acb82786697cf96d8d01a",
+		},
+		sameMessageAggregate: "b6170a00d5542970a765365b3a3ab5b44665a139d743e3c1690f21675e2c4294a09e61e6c6c72ae08d9a71801304b6b402abb747949a91db3d7d4012cfd55dddef567339f0256da5fed0835ce1d0f11bd9ce1326312ca3ad1b324bdb8fb6d795",
+	},
+	{
+		name:        "pop",
+		ciphersuite: bls.PopCiphersuite,
+		signatures: []string{
+			"b6ed936746e01f8ecf281f020953fbf1f01debd5657c4a383940b020b26507f6076334f91e2366c96e9ab279fb5158090352ea1c5b0c9274504f4f0e7053af24802e51e4568d164fe986834f41e55c8e850ce1f98458c0cfc9ab380b55285a55",
+			"af1390c3c47acdb37131a51216da683c509fce0e954328a59f93aebda7e4ff974ba208d9a4a2a2389f892a9d418d618418dd7f7a6bc7aa0da999a9d3a5b815bc085e14fd001f6a1948768a3f4afefc8b8240dda329f984cb345c6363272ba4fe",
+			"ae82747ddeefe4fd64cf9cedb9b04ae3e8a43420cd255e3c7cd06a8d88b7c7f8638543719981c5d16fa3527c468c25f0026704a6951bde891360c7e8d12ddee0559004ccdbe6046b55bae1b257ee97f7cdb955773d7cf29adf3ccbb9975e4eb9",
+		},
+		sameMessageAggregate: "ad38fc73846583b08d110d16ab1d026c6ea77ac2071e8ae832f56ac0cbcdeb9f5678ba5ce42bd8dce334cc47b5abcba40a58f7f1f80ab304193eb98836cc14d8183ec14cc77de0f80c4ffd49e168927a968b5cdaa4cf46b9805be84ad7efa77b",
+	},
+}
+
+func TestCiphersuiteSign(t *testing.T) {
+	for _, v := range ciphersuiteTestVectors {
+		t.Run(v.name, func(t *testing.T) {
+			for i, signature := range v.signatures {
+				privateKey, err := bls.NewPrivateKeyFromBytes(convertHexToPrivateKey(ciphersuitePrivateKeys[i]))
+				require.NoError(t, err)
+				msg := convertHexToMessage(ciphersuiteMessages[i])
+				sig := v.ciphersuite.Sign(privateKey, msg)
+				require.Equal(t, convertHexToSignature(signature), sig.Bytes())
+				require.True(t, v.ciphersuite.VerifySignature(sig, msg, privateKey.PublicKey()))
+
+				valid, err := v.ciphersuite.Verify(convertHexToSignature(signature), msg, convertHexToPublicKey(ciphersuitePublicKeys[i]))
+				require.NoError(t, err)
+				require.True(t, valid)
+			}
+		})
+	}
+}
+
+func TestCiphersuiteReference(t *testing.T) {
+	dsts := map[string][]byte{
+		"basic": []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_NUL_"),
+		"aug":   []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_AUG_"),
+		"pop":   []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"),
+	}
+	for _, v := range ciphersuiteTestVectors {
+		t.Run(v.name, func(t *testing.T) {
+			// The AUG suite signs the public key followed by the message.
+			message := func(publicKey []byte, msg string) []byte {
+				if v.name == "aug" {
+					return append(append([]byte{}, publicKey...), convertHexToMessage(msg)...)
+				}
+				return convertHexToMessage(msg)
+			}
+			aggregate := new(blst.P2Aggregate)
+			for i, signature := range v.signatures {
+				privateKey := convertHexToPrivateKey(ciphersuitePrivateKeys[i])
+				publicKey := convertHexToPublicKey(ciphersuitePublicKeys[i])
+				require.Equal(t, publicKey, referencePublicKey(privateKey))
+				require.Equal(t, convertHexToSignature(signature), referenceSign(privateKey, message(publicKey, ciphersuiteMessages[i]), dsts[v.name]))
+				aggregate.Add(new(blst.P2Affine).Uncompress(referenceSign(privateKey, message(publicKey, ciphersuiteMessages[1]), dsts[v.name])), false)
+			}
+			require.Equal(t, convertHexToSignature(v.sameMessageAggregate), aggregate.ToAffine().Compress())
+		})
+	}
+}
+
+func TestCiphersuiteDomainSeparation(t *testing.T) {
+	// A signature under one suite never verifies under another.
+	for _, v := range ciphersuiteTestVectors {
+		for _, other := range ciphersuiteTestVectors {
+			if v.name == other.name {
+				continue
+			}
+			valid, err := other.ciphersuite.Verify(convertHexToSignature(v.signatures[0]), convertHexToMessage(ciphersuiteMessages[0]), convertHexToPublicKey(ciphersuitePublicKeys[0]))
+			require.NoError(t, err)
+			require.False(t, valid, "%s signature accepted by %s", v.name, other.name)
+		}
+	}
+}
+
+func TestCiphersuiteVerifyAggregate(t *testing.T) {
+	publicKeys := [][]byte{
+		convertHexToPublicKey(ciphersuitePublicKeys[0]),
+		convertHexToPublicKey(ciphersuitePublicKeys[1]),
+		convertHexToPublicKey(ciphersuitePublicKeys[2]),
+	}
+	msg := convertHexToMessage(ciphersuiteMessages[1])
+
+	valid, err := bls.AugCiphersuite.VerifyAggregate(convertHexToSignature(ciphersuiteTestVectors[1].sameMessageAggregate), msg, publicKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	valid, err = bls.PopCiphersuite.VerifyAggregate(convertHexToSignature(ciphersuiteTestVectors[2].sameMessageAggregate), msg, publicKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	// Basic refuses aggregates of the same message, even correct ones.
+	_, err = bls.BasicCiphersuite.VerifyAggregate(convertHexToSignature(ciphersuiteTestVectors[0].sameMessageAggregate), msg, publicKeys)
+	require.ErrorIs(t, err, bls.ErrMessagesNotDistinct)
+
+	valid, err = bls.BasicCiphersuite.VerifyAggregate(convertHexToSignature(ciphersuiteTestVectors[0].signatures[1]), msg, publicKeys[1:2])
+	require.NoError(t, err)
+	require.True(t, valid)
+}
+
+func TestCiphersuiteVerifyMultipleSignatures(t *testing.T) {
+	for _, v := range ciphersuiteTestVectors {
+		t.Run(v.name, func(t *testing.T) {
+			sigs := make([][]byte, len(v.signatures))
+			msgs := make([][]byte, len(v.signatures))
+			publicKeys := make([][]byte, len(v.signatures))
+			for i, signature := range v.signatures {
+				sigs[i] = convertHexToSignature(signature)
+				msgs[i] = convertHexToMessage(ciphersuiteMessages[i])
+				publicKeys[i] = convertHexToPublicKey(ciphersuitePublicKeys[i])
+			}
+			valid, err := v.ciphersuite.VerifyMultipleSignatures(sigs, msgs, publicKeys)
+			require.NoError(t, err)
+			require.True(t, valid)
+
+			sigs[0], sigs[1] = sigs[1], sigs[0]
+			valid, err = v.ciphersuite.VerifyMultipleSignatures(sigs, msgs, publicKeys)
+			require.NoError(t, err)
+			require.False(t, valid)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package bls
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// The context variants check for cancellation between decompression, subgroup checks, aggregation and pairing,
+// and every contextCheckInterval keys within a stage, returning ctx.Err() once the context is done.
+const contextCheckInterval = 64
+
+// VerifyContext is Verify, abandoned once ctx is done.
+func VerifyContext(ctx context.Context, signature []byte, msg []byte, publicKeyBytes []byte) (bool, error) {
+	return PopCiphersuite.verifyContext(ctx, rawSignature(signature), msg, publicKeyBytes)
+}
+
+// VerifyAggregateContext is VerifyAggregate, abandoned once ctx is done.
+func VerifyAggregateContext(ctx context.Context, signature []byte, msg []byte, publicKeysBytes [][]byte) (bool, error) {
+	return PopCiphersuite.verifyAggregateContext(ctx, rawSignature(signature), msg, publicKeysBytes)
+}
+
+// VerifyMultipleSignaturesContext is VerifyMultipleSignatures, abandoned once ctx is done. The Miller loops
+// are run by chunks, so that a cancelled batch stops within a chunk.
+func VerifyMultipleSignaturesContext(ctx context.Context, sigs [][]byte, msgs [][]byte, pubKeys [][]byte) (bool, error) {
+	if len(sigs) == 0 || len(pubKeys) == 0 {
+		return false, nil
+	}
+	length := len(sigs)
+	if length != len(pubKeys) || length != len(msgs) {
+		return false, errors.Errorf("provided signatures, pubkeys and messages have differing lengths. S: %d, P: %d,M %d",
+			length, len(pubKeys), len(msgs))
+	}
+	return verifications.verifyBatch(eth2Curve, sigs, msgs, pubKeys, nil, func(sigs, msgs, pubKeys, _ [][]byte) (bool, error) {
+		return verifyMultipleSignaturesContext(ctx, sigs, msgs, pubKeys)
+	})
+}
+
+func verifyMultipleSignaturesContext(ctx context.Context, sigs [][]byte, msgs [][]byte, pubKeys [][]byte) (bool, error) {
+	publicKeys, err := decodePublicKeysContext(ctx, pubKeys, false)
+	if err != nil {
+		return false, err
+	}
+	signatures, err := decodeSignaturesContext(ctx, sigs)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return false, ctxErr
+		}
+		if errors.Is(err, ErrBatchRandomness) {
+			return false, err
+		}
+		// Like VerifyMultipleSignatures, signatures which cannot be decoded make the batch invalid.
+		return false, nil
+	}
+	entries := make([]batchEntry, len(sigs))
+	for i := range entries {
+		entries[i] = batchEntry{signature: signatures[i], publicKey: publicKeys[i], msg: msgs[i], dst: eth2Curve}
+	}
+	return verifyBatchContext(ctx, entries)
+}
+
+// AggregateSignaturesContext is AggregateSignatures, abandoned once ctx is done.
+func AggregateSignaturesContext(ctx context.Context, sigs [][]byte) ([]byte, error) {
+	if len(sigs) == 0 {
+		return nil, ErrNoSignaturesToAggregate
+	}
+	signatures, err := decodeSignaturesContext(ctx, sigs)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	agg := new(blst.P2Aggregate)
+	agg.Aggregate(signatures, false)
+	return agg.ToAffine().Compress(), nil
+}
+
+// VerifyLazyContext is VerifyLazy, abandoned once ctx is done.
+func VerifyLazyContext(ctx context.Context, signature *LazySignature, msg []byte, publicKey []byte) (bool, error) {
+	return PopCiphersuite.verifyContext(ctx, signature, msg, publicKey)
+}
+
+// VerifyAggregateLazyContext is VerifyAggregateLazy, abandoned once ctx is done.
+func VerifyAggregateLazyContext(ctx context.Context, signature *LazySignature, msg []byte, publicKeys [][]byte) (bool, error) {
+	return PopCiphersuite.verifyAggregateContext(ctx, signature, msg, publicKeys)
+}
+
+// VerifyMultipleLazySignaturesContext is VerifyMultipleLazySignatures, abandoned once ctx is done.
+func VerifyMultipleLazySignaturesContext(ctx context.Context, sigs []*LazySignature, msgs [][]byte, pubKeys [][]byte) (bool, error) {
+	return verifyMultipleLazySignatures(ctx, sigs, msgs, pubKeys, eth2Curve, nil)
+}
+
+// AggregateLazySignaturesContext is AggregateLazySignatures, abandoned once ctx is done.
+func AggregateLazySignaturesContext(ctx context.Context, sigs []*LazySignature) (*Signature, error) {
+	if len(sigs) == 0 {
+		return nil, ErrNoSignaturesToAggregate
+	}
+	signatures, err := decodeLazySignatures(ctx, sigs)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	affines := make([]*blst.P2Affine, len(signatures))
+	for i, signature := range signatures {
+		affines[i] = signature.affine
+	}
+	agg := new(blst.P2Aggregate)
+	agg.Aggregate(affines, false)
+	return &Signature{affine: agg.ToAffine()}, nil
+}
+
+// AggregatePublickKeysContext is AggregatePublickKeys, abandoned once ctx is done.
+func AggregatePublickKeysContext(ctx context.Context, pubs [][]byte) ([]byte, error) {
+	if len(pubs) == 0 {
+		return nil, ErrNoPublicKeysToAggregate
+	}
+	publicKeys, err := decodePublicKeysContext(ctx, pubs, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	affines := make([]*blst.P1Affine, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		affines[i] = publicKey
+	}
+	agg := new(blst.P1Aggregate)
+	agg.Aggregate(affines, false)
+	return agg.ToAffine().Compress(), nil
+}
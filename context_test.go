@@ -0,0 +1,120 @@
+package bls_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextVerification(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 100)
+	ctx := context.Background()
+
+	valid, err := bls.VerifyContext(ctx, sigs[0], msgs[0], pubKeys[0])
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, err = bls.VerifyContext(ctx, sigs[0], msgs[1], pubKeys[0])
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	valid, err = bls.VerifyMultipleSignaturesContext(ctx, sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	aggregate, err := bls.AggregateSignaturesContext(ctx, sigs[:10])
+	require.NoError(t, err)
+	expected, err := bls.AggregateSignatures(sigs[:10])
+	require.NoError(t, err)
+	require.Equal(t, expected, aggregate)
+
+	aggregatePublicKey, err := bls.AggregatePublickKeysContext(ctx, pubKeys[:10])
+	require.NoError(t, err)
+	expected, err = bls.AggregatePublickKeys(pubKeys[:10])
+	require.NoError(t, err)
+	require.Equal(t, expected, aggregatePublicKey)
+
+	privateKey, err := bls.GenerateKey()
+	require.NoError(t, err)
+	msg := []byte("block")
+	var sameMsgSigs, sameMsgPubKeys [][]byte
+	for i := 0; i < 3; i++ {
+		sameMsgSigs = append(sameMsgSigs, privateKey.Sign(msg).Bytes())
+		sameMsgPubKeys = append(sameMsgPubKeys, bls.CompressPublicKey(privateKey.PublicKey()))
+	}
+	aggregate, err = bls.AggregateSignatures(sameMsgSigs)
+	require.NoError(t, err)
+	valid, err = bls.VerifyAggregateContext(ctx, aggregate, msg, sameMsgPubKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	msgs[70] = []byte("tampered")
+	valid, err = bls.VerifyMultipleSignaturesContext(ctx, sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	bls.SetVerifyWorkers(3)
+	defer bls.SetVerifyWorkers(0)
+	cancellable, cancel := context.WithCancel(ctx)
+	defer cancel()
+	valid, err = bls.VerifyMultipleSignaturesContext(cancellable, sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.False(t, valid)
+	msgs[70] = []byte("attestation 70")
+	valid, err = bls.VerifyMultipleSignaturesContext(cancellable, sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+}
+
+func TestContextCancelled(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 10)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	for ctx, expected := range map[context.Context]error{cancelled: context.Canceled, expired: context.DeadlineExceeded} {
+		_, err := bls.VerifyContext(ctx, sigs[0], msgs[0], pubKeys[0])
+		require.ErrorIs(t, err, expected)
+		_, err = bls.VerifyAggregateContext(ctx, sigs[0], msgs[0], pubKeys[:1])
+		require.ErrorIs(t, err, expected)
+		_, err = bls.VerifyMultipleSignaturesContext(ctx, sigs, msgs, pubKeys)
+		require.ErrorIs(t, err, expected)
+		_, err = bls.AggregateSignaturesContext(ctx, sigs)
+		require.ErrorIs(t, err, expected)
+		_, err = bls.AggregatePublickKeysContext(ctx, pubKeys)
+		require.ErrorIs(t, err, expected)
+	}
+}
+
+func TestContextVerificationCache(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 4)
+	bls.SetVerificationCacheSize(16)
+	defer bls.SetVerificationCacheSize(0)
+	ctx := context.Background()
+
+	valid, err := bls.VerifyMultipleSignaturesContext(ctx, sigs[:2], msgs[:2], pubKeys[:2])
+	require.NoError(t, err)
+	require.True(t, valid)
+	// Tuples verified by a context variant are served to the others, and the other way round.
+	valid, err = bls.VerifyContext(ctx, sigs[0], msgs[0], pubKeys[0])
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, err = bls.Verify(sigs[2], msgs[2], pubKeys[2])
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, err = bls.VerifyMultipleSignaturesContext(ctx, sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+	hits, misses := bls.VerificationCacheStats()
+	require.Equal(t, uint64(4), hits)
+	require.Equal(t, uint64(4), misses)
+
+	// A cancelled context wins over the cache.
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = bls.VerifyContext(cancelled, sigs[0], msgs[0], pubKeys[0])
+	require.ErrorIs(t, err, context.Canceled)
+}
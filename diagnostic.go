@@ -0,0 +1,225 @@
+package bls
+
+import (
+	"bytes"
+	"fmt"
+
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// FailureReason tells why a diagnostic verification failed.
+type FailureReason int
+
+const (
+	// ReasonWrongLength is an input of the wrong length.
+	ReasonWrongLength FailureReason = iota + 1
+	// ReasonBadCompressionFlags is an input without the compression flag, or with the infinity flag set
+	// along with other bits.
+	ReasonBadCompressionFlags
+	// ReasonNotOnCurve is an x coordinate which is not a field element or has no point on the curve.
+	ReasonNotOnCurve
+	// ReasonNotInSubgroup is a point on the curve outside of the prime order subgroup.
+	ReasonNotInSubgroup
+	// ReasonInfinity is the point at infinity, where it cannot be valid.
+	ReasonInfinity
+	// ReasonNoPublicKeys is an aggregate verification without public keys.
+	ReasonNoPublicKeys
+	// ReasonPairingMismatch is a well formed signature which does not match the message and public keys.
+	ReasonPairingMismatch
+)
+
+func (r FailureReason) String() string {
+	switch r {
+	case ReasonWrongLength:
+		return "wrong length"
+	case ReasonBadCompressionFlags:
+		return "bad compression flags"
+	case ReasonNotOnCurve:
+		return "not on curve"
+	case ReasonNotInSubgroup:
+		return "not in subgroup"
+	case ReasonInfinity:
+		return "infinity"
+	case ReasonNoPublicKeys:
+		return "no public keys"
+	case ReasonPairingMismatch:
+		return "pairing mismatch"
+	default:
+		return fmt.Sprintf("reason(%d)", int(r))
+	}
+}
+
+// FailedInput tells which input a diagnostic verification failed on.
+type FailedInput int
+
+const (
+	// InputNone is a failure which is not caused by a single input, such as a pairing mismatch.
+	InputNone FailedInput = iota
+	InputSignature
+	InputPublicKey
+)
+
+func (i FailedInput) String() string {
+	switch i {
+	case InputSignature:
+		return "signature"
+	case InputPublicKey:
+		return "public key"
+	default:
+		return "none"
+	}
+}
+
+// VerificationError is the failure reported by the diagnostic verifications. Malformed inputs, which a peer
+// should never send, have an Input, while a pairing mismatch is a well formed but wrong signature.
+type VerificationError struct {
+	Reason FailureReason
+	Input  FailedInput
+	// Index is the index of the failed public key in an aggregate verification.
+	Index int
+}
+
+func (e *VerificationError) Error() string {
+	switch e.Input {
+	case InputSignature:
+		return fmt.Sprintf("bls(diagnostic): signature: %s", e.Reason)
+	case InputPublicKey:
+		return fmt.Sprintf("bls(diagnostic): public key %d: %s", e.Index, e.Reason)
+	default:
+		return fmt.Sprintf("bls(diagnostic): %s", e.Reason)
+	}
+}
+
+// Compression flags of the first byte of a point.
+const (
+	compressionFlag = 0x80
+	infinityFlag    = 0x40
+)
+
+// diagnosePoint returns why b is not a valid compressed point of the given length, 0 for the point at infinity
+// or a point of the curve. uncompress decodes b, false if it is not on the curve.
+func diagnosePoint(b []byte, length int, uncompress func([]byte) bool) (FailureReason, bool) {
+	if len(b) != length {
+		return ReasonWrongLength, false
+	}
+	if b[0]&compressionFlag == 0 {
+		return ReasonBadCompressionFlags, false
+	}
+	if b[0]&infinityFlag != 0 {
+		if b[0] != compressionFlag|infinityFlag || !allZero(b[1:]) {
+			return ReasonBadCompressionFlags, false
+		}
+		return 0, true
+	}
+	if !uncompress(b) {
+		return ReasonNotOnCurve, false
+	}
+	return 0, false
+}
+
+func allZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// diagnosePublicKey decodes a public key, reporting why it is invalid.
+func diagnosePublicKey(b []byte, index int) (PublicKey, *VerificationError) {
+	p := new(blst.P1Affine)
+	reason, infinity := diagnosePoint(b, publicKeyLength, func(b []byte) bool { return p.Uncompress(b) != nil })
+	switch {
+	case reason != 0:
+		return nil, &VerificationError{Reason: reason, Input: InputPublicKey, Index: index}
+	case infinity:
+		return nil, &VerificationError{Reason: ReasonInfinity, Input: InputPublicKey, Index: index}
+	case !p.InG1():
+		return nil, &VerificationError{Reason: ReasonNotInSubgroup, Input: InputPublicKey, Index: index}
+	}
+	return p, nil
+}
+
+// diagnoseSignature decodes a signature, reporting why it is invalid. The infinite signature is decoded since
+// it is valid for an empty aggregate.
+func diagnoseSignature(b []byte) (*Signature, *VerificationError) {
+	p := new(blst.P2Affine)
+	reason, infinity := diagnosePoint(b, signatureLength, func(b []byte) bool { return p.Uncompress(b) != nil })
+	switch {
+	case reason != 0:
+		return nil, &VerificationError{Reason: reason, Input: InputSignature}
+	case infinity:
+		return NewSignature(), nil
+	case !p.InG2():
+		return nil, &VerificationError{Reason: ReasonNotInSubgroup, Input: InputSignature}
+	}
+	return &Signature{affine: p}, nil
+}
+
+// VerifyDiagnostic verifies a signature against one public key like Verify, it returns nil if the signature
+// is valid and a *VerificationError telling what failed otherwise.
+func (c *Ciphersuite) VerifyDiagnostic(signature []byte, msg []byte, publicKeyBytes []byte) error {
+	sig, verr := diagnoseSignature(signature)
+	if verr != nil {
+		return verr
+	}
+	publicKey, verr := diagnosePublicKey(publicKeyBytes, 0)
+	if verr != nil {
+		return verr
+	}
+	// A non zero private key never signs the point at infinity.
+	if bytes.Equal(signature, InfiniteSignature[:]) {
+		return &VerificationError{Reason: ReasonInfinity, Input: InputSignature}
+	}
+	if !c.VerifySignature(sig, msg, publicKey) {
+		return &VerificationError{Reason: ReasonPairingMismatch}
+	}
+	return nil
+}
+
+// VerifyAggregateDiagnostic verifies a signature against many public keys which signed the same message like
+// VerifyAggregate, it returns nil if the signature is valid and a *VerificationError telling what failed
+// otherwise. Errors which are not about the inputs, such as ErrMessagesNotDistinct, are returned as is.
+func (c *Ciphersuite) VerifyAggregateDiagnostic(signature []byte, msg []byte, publicKeysBytes [][]byte) error {
+	sig, verr := diagnoseSignature(signature)
+	if verr != nil {
+		return verr
+	}
+	if len(publicKeysBytes) == 0 {
+		if c.scheme == schemePop && bytes.Equal(signature, InfiniteSignature[:]) {
+			return nil
+		}
+		return &VerificationError{Reason: ReasonNoPublicKeys}
+	}
+	publicKeys := make([]PublicKey, len(publicKeysBytes))
+	for i, publicKey := range publicKeysBytes {
+		if publicKeys[i], verr = diagnosePublicKey(publicKey, i); verr != nil {
+			return verr
+		}
+	}
+	// The aggregate of signatures from non zero private keys is not the point at infinity either.
+	if bytes.Equal(signature, InfiniteSignature[:]) {
+		return &VerificationError{Reason: ReasonInfinity, Input: InputSignature}
+	}
+	valid, err := c.VerifyAggregateSignature(sig, msg, publicKeys)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return &VerificationError{Reason: ReasonPairingMismatch}
+	}
+	return nil
+}
+
+// VerifyDiagnostic verifies a signature against one public key, it returns nil if the signature is valid and
+// a *VerificationError telling what failed otherwise.
+func VerifyDiagnostic(signature []byte, msg []byte, publicKeyBytes []byte) error {
+	return PopCiphersuite.VerifyDiagnostic(signature, msg, publicKeyBytes)
+}
+
+// VerifyAggregateDiagnostic verifies a signature against many public keys which signed the same message, it
+// returns nil if the signature is valid and a *VerificationError telling what failed otherwise.
+func VerifyAggregateDiagnostic(signature []byte, msg []byte, publicKeysBytes [][]byte) error {
+	return PopCiphersuite.VerifyAggregateDiagnostic(signature, msg, publicKeysBytes)
+}
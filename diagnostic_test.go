@@ -0,0 +1,102 @@
+package bls_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// outsideSubgroup returns compressed points on the curve which are not in the prime order subgroup.
+func outsideSubgroup(t *testing.T) (publicKey, signature []byte) {
+	for x := byte(1); publicKey == nil || signature == nil; x++ {
+		b := make([]byte, 96)
+		b[0] = 0x80
+		b[47] = x
+		if p := new(blst.P1Affine).Uncompress(b[:48]); publicKey == nil && p != nil && !p.InG1() {
+			publicKey = append([]byte{}, b[:48]...)
+		}
+		b[95] = x
+		if p := new(blst.P2Affine).Uncompress(b); signature == nil && p != nil && !p.InG2() {
+			signature = b
+		}
+		require.NotEqual(t, byte(0xff), x)
+	}
+	return publicKey, signature
+}
+
+func requireFailure(t *testing.T, err error, reason bls.FailureReason, input bls.FailedInput, index int) {
+	var verr *bls.VerificationError
+	require.True(t, errors.As(err, &verr), "%v", err)
+	require.Equal(t, reason, verr.Reason)
+	require.Equal(t, input, verr.Input)
+	require.Equal(t, index, verr.Index)
+}
+
+func TestVerifyDiagnostic(t *testing.T) {
+	privateKey, err := bls.GenerateKey()
+	require.NoError(t, err)
+	msg := []byte("block")
+	sig := privateKey.Sign(msg).Bytes()
+	publicKey := bls.CompressPublicKey(privateKey.PublicKey())
+	require.NoError(t, bls.VerifyDiagnostic(sig, msg, publicKey))
+
+	badPublicKey, badSignature := outsideSubgroup(t)
+	uncompressed := append([]byte{}, sig...)
+	uncompressed[0] &^= 0x80
+	infinityWithBits := append([]byte{}, bls.InfiniteSignature[:]...)
+	infinityWithBits[95] = 1
+	notOnCurve := make([]byte, 96)
+	for i := range notOnCurve {
+		notOnCurve[i] = 0xff
+	}
+	notOnCurve[0] = 0x9f
+
+	for _, test := range []struct {
+		signature, publicKey []byte
+		reason               bls.FailureReason
+		input                bls.FailedInput
+	}{
+		{sig[:95], publicKey, bls.ReasonWrongLength, bls.InputSignature},
+		{uncompressed, publicKey, bls.ReasonBadCompressionFlags, bls.InputSignature},
+		{infinityWithBits, publicKey, bls.ReasonBadCompressionFlags, bls.InputSignature},
+		{notOnCurve, publicKey, bls.ReasonNotOnCurve, bls.InputSignature},
+		{badSignature, publicKey, bls.ReasonNotInSubgroup, bls.InputSignature},
+		{bls.InfiniteSignature[:], publicKey, bls.ReasonInfinity, bls.InputSignature},
+		{sig, publicKey[1:], bls.ReasonWrongLength, bls.InputPublicKey},
+		{sig, notOnCurve[:48], bls.ReasonNotOnCurve, bls.InputPublicKey},
+		{sig, badPublicKey, bls.ReasonNotInSubgroup, bls.InputPublicKey},
+		{sig, bls.InfiniteSignature[:48], bls.ReasonInfinity, bls.InputPublicKey},
+	} {
+		requireFailure(t, bls.VerifyDiagnostic(test.signature, msg, test.publicKey), test.reason, test.input, 0)
+	}
+	requireFailure(t, bls.VerifyDiagnostic(sig, []byte("other"), publicKey), bls.ReasonPairingMismatch, bls.InputNone, 0)
+}
+
+func TestVerifyAggregateDiagnostic(t *testing.T) {
+	msg := []byte("attestation")
+	var sigs, publicKeys [][]byte
+	for i := 0; i < 4; i++ {
+		privateKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		sigs = append(sigs, privateKey.Sign(msg).Bytes())
+		publicKeys = append(publicKeys, bls.CompressPublicKey(privateKey.PublicKey()))
+	}
+	aggregate, err := bls.AggregateSignatures(sigs)
+	require.NoError(t, err)
+	require.NoError(t, bls.VerifyAggregateDiagnostic(aggregate, msg, publicKeys))
+	require.NoError(t, bls.VerifyAggregateDiagnostic(bls.InfiniteSignature[:], msg, nil))
+
+	requireFailure(t, bls.VerifyAggregateDiagnostic(aggregate, msg, nil), bls.ReasonNoPublicKeys, bls.InputNone, 0)
+	requireFailure(t, bls.VerifyAggregateDiagnostic(aggregate, msg, publicKeys[:3]), bls.ReasonPairingMismatch, bls.InputNone, 0)
+	requireFailure(t, bls.VerifyAggregateDiagnostic(bls.InfiniteSignature[:], msg, publicKeys), bls.ReasonInfinity, bls.InputSignature, 0)
+	requireFailure(t, bls.BasicCiphersuite.VerifyAggregateDiagnostic(bls.InfiniteSignature[:], msg, publicKeys[:1]), bls.ReasonInfinity, bls.InputSignature, 0)
+
+	badPublicKey, _ := outsideSubgroup(t)
+	tampered := append(append([][]byte{}, publicKeys[:2]...), badPublicKey, publicKeys[3])
+	requireFailure(t, bls.VerifyAggregateDiagnostic(aggregate, msg, tampered), bls.ReasonNotInSubgroup, bls.InputPublicKey, 2)
+
+	require.ErrorIs(t, bls.BasicCiphersuite.VerifyAggregateDiagnostic(aggregate, msg, publicKeys), bls.ErrMessagesNotDistinct)
+}
@@ -9,12 +9,28 @@ var (
 	ErrZeroPrivateKey        = errors.New("bls(private): zero key")
 	ErrDeserializePrivateKey = errors.New("bls(private): could not deserialize")
 	// Public key errors
-	ErrDeserializePublicKey = errors.New("bls(public): could not deserialize")
-	ErrInfinitePublicKey    = errors.New("bls(public): infinity")
+	ErrDeserializePublicKey    = errors.New("bls(public): could not deserialize")
+	ErrInfinitePublicKey       = errors.New("bls(public): infinity")
+	ErrNotGroupPublicKey       = errors.New("bls(public): public key is not in group")
+	ErrNoPublicKeysToAggregate = errors.New("bls(public): no public keys to aggregate")
 	// Signature errors
 	ErrDeserializeSignature    = errors.New("bls(signature): could not deserialize")
 	ErrNotGroupSignature       = errors.New("bls(signature): signature is not in group")
 	ErrNoSignaturesToAggregate = errors.New("bls(signature): no signatures to aggregate")
+	ErrMessagesNotDistinct     = errors.New("bls(signature): messages are not distinct")
+	ErrBatchRandomness         = errors.New("bls(signature): could not read batch verification randomness")
+	ErrBatchVerifierClosed     = errors.New("bls(signature): batch verifier closed")
+	ErrLaneFull                = errors.New("bls(signature): verification lane full")
+	ErrSignatureDropped        = errors.New("bls(signature): signature dropped from a full verification lane")
+	ErrBudgetExceeded          = errors.New("bls(signature): verification budget exceeded")
+	// Proof of possession errors
+	ErrInvalidPop = errors.New("bls(pop): invalid proof of possession")
+	// VRF errors
+	ErrInvalidVRFProof = errors.New("bls(vrf): invalid proof")
+	// Quorum certificate errors
+	ErrDuplicateSigner   = errors.New("bls(quorum): duplicate signer")
+	ErrSignerOutOfRange  = errors.New("bls(quorum): signer out of range")
+	ErrInsufficientStake = errors.New("bls(quorum): insufficient stake")
 	// Caching Errors
 	ErrCacheNotEnabled = errors.New("cache(): cache not enabled")
 )
@@ -0,0 +1,47 @@
+package bls
+
+import (
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// EthAggregatePubkeys implements eth_aggregate_pubkeys from the Altair specs.
+// Every public key must pass KeyValidate, the aggregate itself may be the point at infinity.
+func EthAggregatePubkeys(publicKeys []PublicKey) (PublicKey, error) {
+	if len(publicKeys) == 0 {
+		return nil, ErrNoPublicKeysToAggregate
+	}
+	affines := make([]*blst.P1Affine, 0, len(publicKeys))
+	for _, publicKey := range publicKeys {
+		if publicKey == nil || !(*blst.P1Affine)(publicKey).KeyValidate() {
+			return nil, ErrInfinitePublicKey
+		}
+		affines = append(affines, publicKey)
+	}
+	agg := new(blst.P1Aggregate)
+	agg.Aggregate(affines, false)
+	return agg.ToAffine(), nil
+}
+
+// EthFastAggregateVerify implements eth_fast_aggregate_verify from the Altair specs.
+// An empty set of public keys is only valid together with the infinite signature. signature is a *Signature or
+// a *LazySignature, a signature which cannot be decoded is invalid.
+func EthFastAggregateVerify(publicKeys []PublicKey, msg []byte, signature SignatureSource) bool {
+	if missingSignature(signature) {
+		return false
+	}
+	sig, err := signature.Signature()
+	if err != nil {
+		return false
+	}
+	if len(publicKeys) == 0 {
+		return sig.affine.Equals(new(blst.P2Affine))
+	}
+	affines := make([]*blst.P1Affine, 0, len(publicKeys))
+	for _, publicKey := range publicKeys {
+		if publicKey == nil || !(*blst.P1Affine)(publicKey).KeyValidate() {
+			return false
+		}
+		affines = append(affines, publicKey)
+	}
+	return sig.affine.FastAggregateVerify(true, affines, msg, eth2Curve)
+}
@@ -0,0 +1,63 @@
+package bls_test
+
+import (
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func publicKeysFromHex(t *testing.T, hexKeys ...string) []bls.PublicKey {
+	publicKeys := make([]bls.PublicKey, len(hexKeys))
+	for i, h := range hexKeys {
+		publicKey, err := bls.NewPublicKeyFromBytes(convertHexToPublicKey(h))
+		require.NoError(t, err)
+		publicKeys[i] = publicKey
+	}
+	return publicKeys
+}
+
+// Vectors from the consensus-spec eth_aggregate_pubkeys tests.
+func TestEthAggregatePubkeys(t *testing.T) {
+	publicKeys := publicKeysFromHex(t,
+		"a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a",
+		"b301803f8b5ac4a1133581fc676dfedc60d891dd5fa99028805e5ea5b08d3491af75d0707adab3b70c6a6a580217bf81",
+		"b53d21a4cfd562c469cc81514d4ce5a6b577d8403d32a394dc265dd190b47fa9f829fdd7963afdf972e5e77854051f6f",
+	)
+	aggregate, err := bls.EthAggregatePubkeys(publicKeys)
+	require.NoError(t, err)
+	require.Equal(t, convertHexToPublicKey("a095608b35495ca05002b7b5966729dd1ed096568cf2ff24f3318468e0f3495361414a78ebc09574489bc79e48fca969"), bls.CompressPublicKey(aggregate))
+
+	_, err = bls.EthAggregatePubkeys(nil)
+	require.ErrorIs(t, err, bls.ErrNoPublicKeysToAggregate)
+
+	_, err = bls.EthAggregatePubkeys(append(publicKeys, bls.NewPublicKey()))
+	require.ErrorIs(t, err, bls.ErrInfinitePublicKey)
+}
+
+// Vectors from the consensus-spec eth_fast_aggregate_verify tests.
+func TestEthFastAggregateVerify(t *testing.T) {
+	publicKeys := publicKeysFromHex(t,
+		"a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a",
+		"b301803f8b5ac4a1133581fc676dfedc60d891dd5fa99028805e5ea5b08d3491af75d0707adab3b70c6a6a580217bf81",
+		"b53d21a4cfd562c469cc81514d4ce5a6b577d8403d32a394dc265dd190b47fa9f829fdd7963afdf972e5e77854051f6f",
+	)
+	msg := convertHexToMessage("abababababababababababababababababababababababababababababababab")
+	signature, err := bls.NewSignatureFromBytes(convertHexToSignature("9712c3edd73a209c742b8250759db12549b3eaf43b5ca61376d9f30e2747dbcf842d8b2ac0901d2a093713e20284a7670fcf6954e9ab93de991bb9b313e664785a075fc285806fa5224c82bde146561b446ccfc706a64b8579513cfc4ff1d930"))
+	require.NoError(t, err)
+	infiniteSignature, err := bls.NewSignatureFromBytes(bls.InfiniteSignature[:])
+	require.NoError(t, err)
+
+	require.True(t, bls.EthFastAggregateVerify(publicKeys, msg, signature))
+	// extra_pubkey
+	require.False(t, bls.EthFastAggregateVerify(publicKeys[:2], msg, signature))
+	// infinity_pubkey
+	require.False(t, bls.EthFastAggregateVerify(append(publicKeys, bls.NewPublicKey()), msg, signature))
+	// na_pubkeys_and_infinity_signature
+	require.True(t, bls.EthFastAggregateVerify(nil, msg, infiniteSignature))
+	require.True(t, bls.EthFastAggregateVerify([]bls.PublicKey{}, msg, infiniteSignature))
+	// na_pubkeys_and_non_infinity_signature
+	require.False(t, bls.EthFastAggregateVerify(nil, msg, signature))
+	// The infinite signature never verifies against actual keys.
+	require.False(t, bls.EthFastAggregateVerify(publicKeys, msg, infiniteSignature))
+}
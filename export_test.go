@@ -0,0 +1,50 @@
+package bls
+
+import (
+	"context"
+
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// BatchProducts returns the products verifyBatch compares, the Miller loops after the final exponentiation and
+// the weighted sum of the signatures, so that tests can tell which scalar weighted which entry.
+func BatchProducts(sigs [][]byte, msgs [][]byte, pubKeys [][]byte) ([]byte, []byte, error) {
+	entries, _, invalid, err := decodeBatchEntries(sigs, msgs, pubKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(invalid) > 0 {
+		return nil, nil, ErrDeserializeSignature
+	}
+	lhs, sig, err := batchProducts(context.Background(), entries)
+	if err != nil {
+		return nil, nil, err
+	}
+	lhs.FinalExp()
+	return lhs.ToBendian(), sig.Compress(), nil
+}
+
+// MinSigBatchProduct is BatchProducts for a deterministic batch of min-sig signatures of c.
+func (c *Ciphersuite) MinSigBatchProduct(sigs [][]byte, msgs [][]byte, pubKeys [][]byte) ([]byte, error) {
+	rawSigs := make([]*blst.P1Affine, len(sigs))
+	publicKeys := make([]*blst.P2Affine, len(sigs))
+	for i := range sigs {
+		rawSigs[i] = new(blst.P1Affine).Uncompress(sigs[i])
+		pk, err := newMinSigPublicKeyFromBytes(pubKeys[i], false)
+		if err != nil {
+			return nil, err
+		}
+		publicKeys[i] = pk
+	}
+	scalars, bits, err := batchScalars(len(sigs), bytesTranscript(sigs, msgs, pubKeys, c.minSigDST, nil))
+	if err != nil {
+		return nil, err
+	}
+	pairing, ok := minSigPairing(rawSigs, publicKeys, msgs, c.minSigDST, nil, scalars, bits)
+	if !ok {
+		return nil, ErrDeserializeSignature
+	}
+	product := blst.PairingAsFp12(pairing)
+	product.FinalExp()
+	return product.ToBendian(), nil
+}
@@ -0,0 +1,131 @@
+// Package handel implements the Handel aggregation protocol (https://arxiv.org/abs/1906.05132).
+//
+// Nodes are the leaves of a binary tree built over their ids. At level l a node exchanges contributions
+// with the 2^(l-1) nodes of the sibling subtree: its outgoing contribution aggregates its own signature and
+// everything it received at the levels below, so every node ends up with an aggregate of the whole set after
+// log2(n) levels, without any node having to collect all the signatures.
+// Aggregates are verified with a single aggregate public key, keys must come with a proof of possession.
+package handel
+
+import (
+	"math/bits"
+
+	"github.com/Giulio2002/bls"
+)
+
+// Config tunes a Handel node.
+type Config struct {
+	// Threshold is the number of signatures a node waits for before it is done.
+	Threshold int
+	// Fanout is the number of peers a node sends its contribution to, per level and per tick.
+	Fanout int
+	// Verifications is the number of incoming contributions a node verifies per tick.
+	Verifications int
+	// LevelTimeout is the number of ticks after which a level starts even if the levels below are incomplete.
+	LevelTimeout int
+	// Window is the initial number of best ranked peers whose contributions are verified first, per level.
+	Window int
+}
+
+// DefaultConfig returns the default configuration for the given threshold.
+func DefaultConfig(threshold int) Config {
+	return Config{
+		Threshold:     threshold,
+		Fanout:        1,
+		Verifications: 2,
+		LevelTimeout:  2,
+		Window:        16,
+	}
+}
+
+// Network sends packets to other nodes, delivery does not need to be reliable nor ordered.
+// The receiving side hands the packets to Node.Receive.
+type Network interface {
+	Send(to int, packet *Packet)
+}
+
+// Packet is the contribution of a node at one level.
+type Packet struct {
+	Origin int
+	Level  int
+	// MultiSig aggregates the signatures of the subtree of the origin at this level.
+	MultiSig *MultiSignature
+	// Individual is the signature of the origin alone, it can complete a better aggregate.
+	Individual []byte
+}
+
+// MultiSignature is an aggregate signature together with the bitfield of its signers.
+type MultiSignature struct {
+	Bitfield  []byte
+	Signature []byte
+}
+
+// Cardinality returns the number of signers of the multisignature.
+func (m *MultiSignature) Cardinality() int {
+	count := 0
+	for _, b := range m.Bitfield {
+		count += bits.OnesCount8(b)
+	}
+	return count
+}
+
+// Signers returns the ids of the signers of the multisignature in a set of n nodes, the bits past n are ignored.
+func (m *MultiSignature) Signers(n int) []int {
+	var signers []int
+	for i := 0; i < min(len(m.Bitfield)*8, n); i++ {
+		if hasBit(m.Bitfield, i) {
+			signers = append(signers, i)
+		}
+	}
+	return signers
+}
+
+// merge aggregates two multisignatures with disjoint signers.
+func (m *MultiSignature) merge(other *MultiSignature) (*MultiSignature, error) {
+	signature, err := bls.AggregateSignatures([][]byte{m.Signature, other.Signature})
+	if err != nil {
+		return nil, err
+	}
+	bitfield := make([]byte, len(m.Bitfield))
+	for i := range bitfield {
+		bitfield[i] = m.Bitfield[i] | other.Bitfield[i]
+	}
+	return &MultiSignature{Bitfield: bitfield, Signature: signature}, nil
+}
+
+func newBitfield(n int) []byte {
+	return make([]byte, (n+7)/8)
+}
+
+func hasBit(bitfield []byte, i int) bool {
+	return bitfield[i/8]&(1<<(i%8)) != 0
+}
+
+func setBit(bitfield []byte, i int) {
+	bitfield[i/8] |= 1 << (i % 8)
+}
+
+// levels returns the number of levels of a tree over n nodes.
+func levels(n int) int {
+	return bits.Len(uint(n - 1))
+}
+
+// subtree returns the ids of the subtree of size 2^(level-1) which contains id.
+func subtree(id, level, n int) []int {
+	start := id >> (level - 1) << (level - 1)
+	end := min(start+1<<(level-1), n)
+	ids := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		ids = append(ids, i)
+	}
+	return ids
+}
+
+// peers returns the ids a node exchanges contributions with at a level, the sibling of its subtree.
+func peers(id, level, n int) []int {
+	sibling := id ^ (1 << (level - 1))
+	if sibling>>(level-1)<<(level-1) >= n {
+		return nil
+	}
+	return subtree(sibling, level, n)
+}
@@ -0,0 +1,130 @@
+package handel_test
+
+import (
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/Giulio2002/bls/handel"
+	"github.com/stretchr/testify/require"
+)
+
+func generateKeys(t *testing.T, n int) []*bls.PrivateKey {
+	privateKeys := make([]*bls.PrivateKey, n)
+	for i := range privateKeys {
+		privateKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		privateKeys[i] = privateKey
+	}
+	return privateKeys
+}
+
+// requireValidMultiSignature checks the aggregate of every honest node against the keys of its signers.
+func requireValidMultiSignature(t *testing.T, s *handel.Simulator, privateKeys []*bls.PrivateKey, msg []byte, threshold int, excluded ...int) {
+	for i := range privateKeys {
+		node := s.Node(i)
+		if node == nil {
+			continue
+		}
+		multiSig := node.MultiSignature()
+		require.GreaterOrEqual(t, multiSig.Cardinality(), threshold)
+		var publicKeys [][]byte
+		for _, signer := range multiSig.Signers(len(privateKeys)) {
+			require.NotContains(t, excluded, signer)
+			publicKeys = append(publicKeys, bls.CompressPublicKey(privateKeys[signer].PublicKey()))
+		}
+		valid, err := bls.VerifyAggregate(multiSig.Signature, msg, publicKeys)
+		require.NoError(t, err)
+		require.True(t, valid)
+	}
+}
+
+func TestHandelConverges(t *testing.T) {
+	bls.SetEnabledCaching(true)
+	t.Cleanup(func() { bls.SetEnabledCaching(false) })
+	msg := []byte("handel")
+	for _, n := range []int{1, 2, 13, 32} {
+		privateKeys := generateKeys(t, n)
+		s, err := handel.NewSimulator(privateKeys, msg, handel.SimulatorConfig{Config: handel.DefaultConfig(n)})
+		require.NoError(t, err)
+		require.True(t, s.Run(100), "n=%d", n)
+		t.Logf("%d nodes converged in %d rounds", n, s.Rounds())
+		requireValidMultiSignature(t, s, privateKeys, msg, n)
+	}
+}
+
+func TestHandelByzantine(t *testing.T) {
+	bls.SetEnabledCaching(true)
+	t.Cleanup(func() { bls.SetEnabledCaching(false) })
+	msg := []byte("handel")
+	n := 32
+	byzantine := []int{3, 9, 17, 30}
+	offline := []int{5, 22}
+	threshold := n - len(byzantine) - len(offline)
+	privateKeys := generateKeys(t, n)
+	simulate := func() *handel.Simulator {
+		s, err := handel.NewSimulator(privateKeys, msg, handel.SimulatorConfig{
+			Config:    handel.DefaultConfig(threshold),
+			Byzantine: byzantine,
+			Offline:   offline,
+			DropRate:  0.1,
+			Seed:      1,
+		})
+		require.NoError(t, err)
+		require.True(t, s.Run(200))
+		return s
+	}
+	s := simulate()
+	t.Logf("%d nodes with %d byzantine and %d offline converged in %d rounds", n, len(byzantine), len(offline), s.Rounds())
+	requireValidMultiSignature(t, s, privateKeys, msg, threshold, append(byzantine, offline...)...)
+
+	// The seed replays the same run.
+	for i := 0; i < 3; i++ {
+		replay := simulate()
+		require.Equal(t, s.Rounds(), replay.Rounds())
+		for id := 0; id < n; id++ {
+			if node := s.Node(id); node != nil {
+				require.Equal(t, node.MultiSignature(), replay.Node(id).MultiSignature(), "node %d", id)
+			}
+		}
+	}
+}
+
+func TestHandelInvalidConfig(t *testing.T) {
+	privateKeys := generateKeys(t, 4)
+	_, err := handel.NewSimulator(privateKeys, []byte("handel"), handel.SimulatorConfig{Config: handel.DefaultConfig(5)})
+	require.Error(t, err)
+	_, err = handel.NewSimulator(nil, []byte("handel"), handel.SimulatorConfig{Config: handel.DefaultConfig(1)})
+	require.Error(t, err)
+
+	publicKeys := [][]byte{bls.CompressPublicKey(privateKeys[0].PublicKey())}
+	_, err = handel.NewNode(0, publicKeys, privateKeys[1], []byte("handel"), &handel.MemoryNetwork{}, handel.DefaultConfig(1))
+	require.Error(t, err)
+	_, err = handel.NewNode(1, publicKeys, privateKeys[0], []byte("handel"), &handel.MemoryNetwork{}, handel.DefaultConfig(1))
+	require.Error(t, err)
+}
+
+func TestHandelPaddingBits(t *testing.T) {
+	msg := []byte("handel")
+	privateKeys := generateKeys(t, 5)
+	publicKeys := make([][]byte, len(privateKeys))
+	for i, privateKey := range privateKeys {
+		publicKeys[i] = bls.CompressPublicKey(privateKey.PublicKey())
+	}
+	node, err := handel.NewNode(4, publicKeys, privateKeys[4], msg, &handel.MemoryNetwork{}, handel.DefaultConfig(5))
+	require.NoError(t, err)
+
+	// Node 0 contributes to node 4 at level 3, with the padding bit 7 of the bitfield set.
+	node.Receive(&handel.Packet{
+		Origin: 0,
+		Level:  3,
+		MultiSig: &handel.MultiSignature{
+			Bitfield:  []byte{1<<0 | 1<<7},
+			Signature: privateKeys[0].Sign(msg).Bytes(),
+		},
+	})
+	require.NotPanics(t, node.Tick)
+	require.Equal(t, []int{4}, node.MultiSignature().Signers(len(publicKeys)))
+
+	padded := &handel.MultiSignature{Bitfield: []byte{1<<0 | 1<<7}}
+	require.Equal(t, []int{0}, padded.Signers(len(publicKeys)))
+}
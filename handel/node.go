@@ -0,0 +1,344 @@
+package handel
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Giulio2002/bls"
+)
+
+// level is the state of a node at one level of the tree.
+type level struct {
+	number int
+	// Peers of the level, the ids in [start, end), ranked by verification priority.
+	peers      []int
+	rank       map[int]int
+	start, end int
+	// Number of nodes in our own subtree at this level, the size of a complete outgoing contribution.
+	ownSize int
+
+	nextPeer int
+	window   int
+
+	// best is the largest verified aggregate received, individuals the verified individual signatures.
+	best        *MultiSignature
+	individuals map[int][]byte
+	// incoming combines best with the individual signatures it misses.
+	incoming *MultiSignature
+	pending  []*Packet
+}
+
+// Node is a participant of the Handel protocol, it is driven by calls to Receive and Tick.
+type Node struct {
+	id         int
+	publicKeys [][]byte
+	msg        []byte
+	signature  []byte
+	network    Network
+	config     Config
+
+	mu        sync.Mutex
+	tick      int
+	levels    []*level
+	outgoing  []*MultiSignature
+	blacklist map[int]bool
+	done      bool
+}
+
+// NewNode creates the node id of the set publicKeys, it signs msg with privateKey.
+func NewNode(id int, publicKeys [][]byte, privateKey *bls.PrivateKey, msg []byte, network Network, config Config) (*Node, error) {
+	if id < 0 || id >= len(publicKeys) {
+		return nil, fmt.Errorf("handel: node id %d out of range", id)
+	}
+	if !bytes.Equal(bls.CompressPublicKey(privateKey.PublicKey()), publicKeys[id]) {
+		return nil, errors.New("handel: private key does not match the public key of the node")
+	}
+	if config.Threshold <= 0 || config.Threshold > len(publicKeys) {
+		return nil, fmt.Errorf("handel: threshold should be between 1 and %d", len(publicKeys))
+	}
+	n := &Node{
+		id:         id,
+		publicKeys: publicKeys,
+		msg:        msg,
+		signature:  privateKey.Sign(msg).Bytes(),
+		network:    network,
+		config:     config,
+		blacklist:  make(map[int]bool),
+	}
+	for l := 1; l <= levels(len(publicKeys)); l++ {
+		lv := &level{
+			number:      l,
+			peers:       peers(id, l, len(publicKeys)),
+			rank:        make(map[int]int),
+			ownSize:     len(subtree(id, l, len(publicKeys))),
+			window:      max(config.Window, 1),
+			individuals: make(map[int][]byte),
+		}
+		if len(lv.peers) > 0 {
+			lv.start, lv.end = lv.peers[0], lv.peers[len(lv.peers)-1]+1
+		}
+		rankPeers(id, lv.peers)
+		for r, peer := range lv.peers {
+			lv.rank[peer] = r
+		}
+		n.levels = append(n.levels, lv)
+	}
+	own := &MultiSignature{Bitfield: newBitfield(len(publicKeys)), Signature: n.signature}
+	setBit(own.Bitfield, id)
+	n.outgoing = []*MultiSignature{own}
+	if err := n.updateOutgoing(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// rankPeers shuffles peers in an order specific to id, so that every peer is favoured by some nodes.
+func rankPeers(id int, peers []int) {
+	score := func(peer int) []byte {
+		var b [16]byte
+		binary.BigEndian.PutUint64(b[:8], uint64(id))
+		binary.BigEndian.PutUint64(b[8:], uint64(peer))
+		h := sha256.Sum256(b[:])
+		return h[:]
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		return bytes.Compare(score(peers[i]), score(peers[j])) < 0
+	})
+}
+
+// ID returns the id of the node.
+func (n *Node) ID() int {
+	return n.id
+}
+
+// Done reports whether the node has an aggregate of at least Threshold signatures.
+func (n *Node) Done() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.done
+}
+
+// MultiSignature returns the best aggregate of the node so far.
+func (n *Node) MultiSignature() *MultiSignature {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.outgoing[len(n.outgoing)-1]
+}
+
+// Receive queues a packet for verification, malformed packets are dropped right away.
+func (n *Node) Receive(p *Packet) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if p == nil || p.MultiSig == nil || p.Level < 1 || p.Level > len(n.levels) || n.blacklist[p.Origin] {
+		return
+	}
+	lv := n.levels[p.Level-1]
+	if _, ok := lv.rank[p.Origin]; !ok {
+		return
+	}
+	if len(p.MultiSig.Bitfield) != len(n.outgoing[0].Bitfield) || !hasBit(p.MultiSig.Bitfield, p.Origin) {
+		return
+	}
+	// Only the subtree of the origin may have signed, the padding bits past the last node must be unset.
+	for i := 0; i < len(p.MultiSig.Bitfield)*8; i++ {
+		if hasBit(p.MultiSig.Bitfield, i) && (i < lv.start || i >= lv.end) {
+			return
+		}
+	}
+	lv.pending = append(lv.pending, p)
+}
+
+// Tick verifies the most promising pending contributions and sends ours to the next peers.
+func (n *Node) Tick() {
+	n.mu.Lock()
+	n.tick++
+	updated := false
+	for i := 0; i < n.config.Verifications; i++ {
+		lv, p := n.nextPending()
+		if p == nil {
+			break
+		}
+		if n.verify(lv, p) {
+			updated = true
+		}
+	}
+	if updated {
+		// Aggregation only fails on invalid signatures, which were all verified.
+		_ = n.updateOutgoing()
+	}
+	sends := n.disseminate()
+	n.mu.Unlock()
+
+	for _, send := range sends {
+		n.network.Send(send.to, send.packet)
+	}
+}
+
+// gain returns how many signatures verifying p would add to the incoming contribution of the level.
+func (n *Node) gain(lv *level, p *Packet) int {
+	union := p.MultiSig.Cardinality()
+	for signer := range lv.individuals {
+		if !hasBit(p.MultiSig.Bitfield, signer) {
+			union++
+		}
+	}
+	if lv.incoming == nil {
+		return union
+	}
+	return union - lv.incoming.Cardinality()
+}
+
+// nextPending drops the useless pending packets and pops the one to verify next. Packets from peers ranked
+// inside the window come first, then the ones adding the most signatures, from the lowest level.
+func (n *Node) nextPending() (*level, *Packet) {
+	var (
+		bestLevel  *level
+		bestIndex  int
+		bestWindow bool
+		bestGain   int
+	)
+	for _, lv := range n.levels {
+		pending := lv.pending[:0]
+		for _, p := range lv.pending {
+			if !n.blacklist[p.Origin] && n.gain(lv, p) > 0 {
+				pending = append(pending, p)
+			}
+		}
+		lv.pending = pending
+		for i, p := range lv.pending {
+			inWindow := lv.rank[p.Origin] < lv.window
+			gain := n.gain(lv, p)
+			better := bestLevel == nil ||
+				(inWindow && !bestWindow) ||
+				(inWindow == bestWindow && gain > bestGain) ||
+				(inWindow == bestWindow && gain == bestGain && lv == bestLevel && lv.rank[p.Origin] < lv.rank[bestLevel.pending[bestIndex].Origin])
+			if better {
+				bestLevel, bestIndex, bestWindow, bestGain = lv, i, inWindow, gain
+			}
+		}
+	}
+	if bestLevel == nil {
+		return nil, nil
+	}
+	p := bestLevel.pending[bestIndex]
+	bestLevel.pending = append(bestLevel.pending[:bestIndex], bestLevel.pending[bestIndex+1:]...)
+	return bestLevel, p
+}
+
+// verify checks a contribution and merges it into the level, invalid contributions blacklist their origin.
+func (n *Node) verify(lv *level, p *Packet) bool {
+	if !n.verifyContribution(p) {
+		n.blacklist[p.Origin] = true
+		lv.window = max(lv.window/4, 1)
+		return false
+	}
+	lv.window = min(lv.window*2, len(lv.peers))
+	if _, ok := lv.individuals[p.Origin]; !ok && p.Individual != nil {
+		lv.individuals[p.Origin] = p.Individual
+	}
+	if lv.best == nil || p.MultiSig.Cardinality() > lv.best.Cardinality() {
+		lv.best = p.MultiSig
+	}
+
+	incoming := lv.best
+	signers := make([]int, 0, len(lv.individuals))
+	for signer := range lv.individuals {
+		signers = append(signers, signer)
+	}
+	sort.Ints(signers)
+	for _, signer := range signers {
+		if hasBit(incoming.Bitfield, signer) {
+			continue
+		}
+		individual := &MultiSignature{Bitfield: newBitfield(len(n.publicKeys)), Signature: lv.individuals[signer]}
+		setBit(individual.Bitfield, signer)
+		merged, err := incoming.merge(individual)
+		if err != nil {
+			return false
+		}
+		incoming = merged
+	}
+	lv.incoming = incoming
+	return true
+}
+
+func (n *Node) verifyContribution(p *Packet) bool {
+	signers := p.MultiSig.Signers(len(n.publicKeys))
+	publicKeys := make([][]byte, len(signers))
+	for i, signer := range signers {
+		publicKeys[i] = n.publicKeys[signer]
+	}
+	aggregate, err := bls.AggregatePublickKeys(publicKeys)
+	if err != nil {
+		return false
+	}
+	if valid, err := bls.Verify(p.MultiSig.Signature, n.msg, aggregate); err != nil || !valid {
+		return false
+	}
+	if _, ok := n.levels[p.Level-1].individuals[p.Origin]; ok || p.Individual == nil {
+		return true
+	}
+	valid, err := bls.Verify(p.Individual, n.msg, n.publicKeys[p.Origin])
+	return err == nil && valid
+}
+
+// updateOutgoing recomputes the outgoing contribution of every level, the last one is the total.
+func (n *Node) updateOutgoing() error {
+	outgoing := n.outgoing[:1]
+	current := outgoing[0]
+	for _, lv := range n.levels {
+		if lv.incoming != nil {
+			merged, err := current.merge(lv.incoming)
+			if err != nil {
+				return err
+			}
+			current = merged
+		}
+		outgoing = append(outgoing, current)
+	}
+	n.outgoing = outgoing
+	if current.Cardinality() >= n.config.Threshold {
+		n.done = true
+	}
+	return nil
+}
+
+type send struct {
+	to     int
+	packet *Packet
+}
+
+// disseminate picks the next peers of every started level. A level starts once our contribution to it is
+// complete, or after LevelTimeout ticks per level below it.
+func (n *Node) disseminate() []send {
+	var sends []send
+	for i, lv := range n.levels {
+		if len(lv.peers) == 0 {
+			continue
+		}
+		contribution := n.outgoing[i]
+		if n.tick <= i*n.config.LevelTimeout && contribution.Cardinality() < lv.ownSize {
+			continue
+		}
+		for sent, tried := 0, 0; sent < n.config.Fanout && tried < len(lv.peers); tried++ {
+			peer := lv.peers[lv.nextPeer]
+			lv.nextPeer = (lv.nextPeer + 1) % len(lv.peers)
+			if n.blacklist[peer] {
+				continue
+			}
+			sends = append(sends, send{to: peer, packet: &Packet{
+				Origin:     n.id,
+				Level:      lv.number,
+				MultiSig:   contribution,
+				Individual: n.signature,
+			}})
+			sent++
+		}
+	}
+	return sends
+}
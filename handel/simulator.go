@@ -0,0 +1,170 @@
+package handel
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/Giulio2002/bls"
+)
+
+// MemoryNetwork is an in-memory Network, packets are queued until the simulator delivers them.
+type MemoryNetwork struct {
+	mu    sync.Mutex
+	queue []send
+}
+
+func (m *MemoryNetwork) Send(to int, packet *Packet) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queue = append(m.queue, send{to: to, packet: packet})
+}
+
+func (m *MemoryNetwork) flush() []send {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	queue := m.queue
+	m.queue = nil
+	return queue
+}
+
+// SimulatorConfig describes a simulated run.
+type SimulatorConfig struct {
+	Config Config
+	// Offline nodes never send nor receive anything.
+	Offline []int
+	// Byzantine nodes send contributions claiming their whole subtree with invalid signatures.
+	Byzantine []int
+	// DropRate is the probability for a packet to be lost.
+	DropRate float64
+	Seed     int64
+}
+
+// Simulator runs Handel between nodes in memory, a round is one tick of every node followed by the
+// delivery of the packets sent during the round.
+type Simulator struct {
+	nodes     []*Node
+	n         int
+	network   *MemoryNetwork
+	byzantine map[int]*bls.PrivateKey
+	// byzantineIDs are the keys of byzantine in order, so that a seed replays the same attacks.
+	byzantineIDs []int
+	nextPeer     map[int]int
+	rng          *rand.Rand
+	dropRate     float64
+	forgedMsg    []byte
+	rounds       int
+	publicKeys   [][]byte
+}
+
+// NewSimulator creates a simulation where node i holds privateKeys[i] and every node signs msg.
+func NewSimulator(privateKeys []*bls.PrivateKey, msg []byte, config SimulatorConfig) (*Simulator, error) {
+	if len(privateKeys) == 0 {
+		return nil, errors.New("handel: no nodes to simulate")
+	}
+	s := &Simulator{
+		nodes:     make([]*Node, len(privateKeys)),
+		n:         len(privateKeys),
+		network:   &MemoryNetwork{},
+		byzantine: make(map[int]*bls.PrivateKey),
+		nextPeer:  make(map[int]int),
+		rng:       rand.New(rand.NewSource(config.Seed)),
+		dropRate:  config.DropRate,
+		forgedMsg: append([]byte("forged "), msg...),
+	}
+	s.publicKeys = make([][]byte, len(privateKeys))
+	for i, privateKey := range privateKeys {
+		s.publicKeys[i] = bls.CompressPublicKey(privateKey.PublicKey())
+	}
+	honest := make(map[int]bool, len(privateKeys))
+	for i := range privateKeys {
+		honest[i] = true
+	}
+	for _, id := range config.Offline {
+		honest[id] = false
+	}
+	for _, id := range config.Byzantine {
+		honest[id] = false
+		s.byzantine[id] = privateKeys[id]
+	}
+	for id := range s.byzantine {
+		s.byzantineIDs = append(s.byzantineIDs, id)
+	}
+	sort.Ints(s.byzantineIDs)
+	for i, privateKey := range privateKeys {
+		if !honest[i] {
+			continue
+		}
+		node, err := NewNode(i, s.publicKeys, privateKey, msg, s.network, config.Config)
+		if err != nil {
+			return nil, err
+		}
+		s.nodes[i] = node
+	}
+	return s, nil
+}
+
+// Node returns the node id, nil if it is offline or byzantine.
+func (s *Simulator) Node(id int) *Node {
+	return s.nodes[id]
+}
+
+// Rounds returns the number of rounds run so far.
+func (s *Simulator) Rounds() int {
+	return s.rounds
+}
+
+// Done reports whether every honest node reached the threshold.
+func (s *Simulator) Done() bool {
+	for _, node := range s.nodes {
+		if node != nil && !node.Done() {
+			return false
+		}
+	}
+	return true
+}
+
+// Step runs one round.
+func (s *Simulator) Step() {
+	for _, node := range s.nodes {
+		if node != nil {
+			node.Tick()
+		}
+	}
+	for _, id := range s.byzantineIDs {
+		s.attack(id, s.byzantine[id])
+	}
+	for _, send := range s.network.flush() {
+		if s.nodes[send.to] == nil || s.rng.Float64() < s.dropRate {
+			continue
+		}
+		s.nodes[send.to].Receive(send.packet)
+	}
+	s.rounds++
+}
+
+// Run steps until every honest node is done or maxRounds is reached, it returns whether they are done.
+func (s *Simulator) Run(maxRounds int) bool {
+	for s.rounds < maxRounds && !s.Done() {
+		s.Step()
+	}
+	return s.Done()
+}
+
+// attack sends, at every level, a contribution claiming the whole subtree of id signed a forged message.
+func (s *Simulator) attack(id int, privateKey *bls.PrivateKey) {
+	forged := privateKey.Sign(s.forgedMsg).Bytes()
+	for l := 1; l <= levels(s.n); l++ {
+		levelPeers := peers(id, l, s.n)
+		if len(levelPeers) == 0 {
+			continue
+		}
+		multiSig := &MultiSignature{Bitfield: newBitfield(s.n), Signature: forged}
+		for _, signer := range subtree(id, l, s.n) {
+			setBit(multiSig.Bitfield, signer)
+		}
+		peer := levelPeers[s.rng.Intn(len(levelPeers))]
+		s.network.Send(peer, &Packet{Origin: id, Level: l, MultiSig: multiSig, Individual: forged})
+	}
+}
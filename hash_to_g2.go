@@ -0,0 +1,282 @@
+package bls
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// blst only hashes messages it holds in memory. The hash to G2 below follows RFC 9380 step by step
+// so that the message can be consumed from a stream, only the final point arithmetic is left to blst.
+
+// Lengths used by hash_to_field for BLS12-381 G2 (k = 128, m = 2, count = 2).
+const (
+	hashToFieldL      = 64
+	hashToFieldLength = 2 * 2 * hashToFieldL
+)
+
+var errDSTTooLong = errors.New("bls(hash): dst is longer than 255 bytes")
+
+func mustHex(h string) *big.Int {
+	n, ok := new(big.Int).SetString(h, 16)
+	if !ok {
+		panic("bls: invalid constant " + h)
+	}
+	return n
+}
+
+var (
+	fieldModulus = mustHex("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab")
+	// (p - 3) / 4 and (p - 1) / 2, used by the square root.
+	sqrtExponent     = new(big.Int).Rsh(new(big.Int).Sub(fieldModulus, big.NewInt(3)), 2)
+	legendreExponent = new(big.Int).Rsh(new(big.Int).Sub(fieldModulus, big.NewInt(1)), 1)
+	// Effective cofactor of G2, little endian as expected by blst.
+	g2CofactorLE = reverseBytes(mustHex("bc69f08f2ee75b3584c6a0ea91b352888e2a8e9145ad7689986ff031508ffe1329c2f178731db956d82bf015d1212b02ec0ec69d7477c1ae954cbc06689f6a359894c0adebbf6b4e8020005aaa95551").Bytes())
+)
+
+// fp2 is an element c0 + c1 * i of Fp2 = Fp[i] / (i^2 + 1).
+type fp2 struct {
+	c0, c1 *big.Int
+}
+
+func newFp2(c0, c1 *big.Int) fp2 {
+	return fp2{c0: new(big.Int).Mod(c0, fieldModulus), c1: new(big.Int).Mod(c1, fieldModulus)}
+}
+
+func fp2FromHex(c0, c1 string) fp2 {
+	return newFp2(mustHex(c0), mustHex(c1))
+}
+
+func (a fp2) add(b fp2) fp2 {
+	return newFp2(new(big.Int).Add(a.c0, b.c0), new(big.Int).Add(a.c1, b.c1))
+}
+
+func (a fp2) neg() fp2 {
+	return newFp2(new(big.Int).Neg(a.c0), new(big.Int).Neg(a.c1))
+}
+
+func (a fp2) mul(b fp2) fp2 {
+	c0 := new(big.Int).Sub(new(big.Int).Mul(a.c0, b.c0), new(big.Int).Mul(a.c1, b.c1))
+	c1 := new(big.Int).Add(new(big.Int).Mul(a.c0, b.c1), new(big.Int).Mul(a.c1, b.c0))
+	return newFp2(c0, c1)
+}
+
+func (a fp2) square() fp2 {
+	return a.mul(a)
+}
+
+// inv returns 1 / a, or 0 when a is 0 (inv0 in RFC 9380).
+func (a fp2) inv() fp2 {
+	norm := new(big.Int).Add(new(big.Int).Mul(a.c0, a.c0), new(big.Int).Mul(a.c1, a.c1))
+	norm.Mod(norm, fieldModulus)
+	if norm.Sign() == 0 {
+		return newFp2(new(big.Int), new(big.Int))
+	}
+	norm.ModInverse(norm, fieldModulus)
+	return newFp2(new(big.Int).Mul(a.c0, norm), new(big.Int).Neg(new(big.Int).Mul(a.c1, norm)))
+}
+
+func (a fp2) exp(e *big.Int) fp2 {
+	result := newFp2(big.NewInt(1), new(big.Int))
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		result = result.square()
+		if e.Bit(i) == 1 {
+			result = result.mul(a)
+		}
+	}
+	return result
+}
+
+func (a fp2) isZero() bool {
+	return a.c0.Sign() == 0 && a.c1.Sign() == 0
+}
+
+func (a fp2) equal(b fp2) bool {
+	return a.c0.Cmp(b.c0) == 0 && a.c1.Cmp(b.c1) == 0
+}
+
+// sgn0 as defined for extension fields of degree 2 in RFC 9380.
+func (a fp2) sgn0() uint {
+	sign0 := a.c0.Bit(0)
+	sign1 := a.c1.Bit(0)
+	if a.c0.Sign() == 0 {
+		return sign1
+	}
+	return sign0
+}
+
+// sqrt returns a square root of a, the second return value is false when there is none.
+// This is algorithm 9 of https://eprint.iacr.org/2012/685.pdf, which needs p = 3 mod 4.
+func (a fp2) sqrt() (fp2, bool) {
+	minusOne := newFp2(big.NewInt(-1), new(big.Int))
+	a1 := a.exp(sqrtExponent)
+	alpha := a1.square().mul(a)
+	// alpha^p is the conjugate of alpha.
+	a0 := newFp2(alpha.c0, new(big.Int).Neg(alpha.c1)).mul(alpha)
+	if a0.equal(minusOne) {
+		return fp2{}, false
+	}
+	x0 := a1.mul(a)
+	var x fp2
+	if alpha.equal(minusOne) {
+		x = newFp2(new(big.Int).Neg(x0.c1), x0.c0)
+	} else {
+		b := alpha.add(newFp2(big.NewInt(1), new(big.Int))).exp(legendreExponent)
+		x = b.mul(x0)
+	}
+	if !x.square().equal(a) {
+		return fp2{}, false
+	}
+	return x, true
+}
+
+// Simplified SWU parameters for the curve 3-isogenous to G2.
+var (
+	sswuA = fp2FromHex("0", "f0")
+	sswuB = fp2FromHex("3f4", "3f4")
+	sswuZ = newFp2(big.NewInt(-2), big.NewInt(-1))
+)
+
+// Coefficients of the 3-isogeny map to G2, lowest degree first.
+var (
+	isoXNum = []fp2{
+		fp2FromHex("5c759507e8e333ebb5b7a9a47d7ed8532c52d39fd3a042a88b58423c50ae15d5c2638e343d9c71c6238aaaaaaaa97d6", "5c759507e8e333ebb5b7a9a47d7ed8532c52d39fd3a042a88b58423c50ae15d5c2638e343d9c71c6238aaaaaaaa97d6"),
+		fp2FromHex("0", "11560bf17baa99bc32126fced787c88f984f87adf7ae0c7f9a208c6b4f20a4181472aaa9cb8d555526a9ffffffffc71a"),
+		fp2FromHex("11560bf17baa99bc32126fced787c88f984f87adf7ae0c7f9a208c6b4f20a4181472aaa9cb8d555526a9ffffffffc71e", "8ab05f8bdd54cde190937e76bc3e447cc27c3d6fbd7063fcd104635a790520c0a395554e5c6aaaa9354ffffffffe38d"),
+		fp2FromHex("171d6541fa38ccfaed6dea691f5fb614cb14b4e7f4e810aa22d6108f142b85757098e38d0f671c7188e2aaaaaaaa5ed1", "0"),
+	}
+	isoXDen = []fp2{
+		fp2FromHex("0", "1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaa63"),
+		fp2FromHex("c", "1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaa9f"),
+		fp2FromHex("1", "0"),
+	}
+	isoYNum = []fp2{
+		fp2FromHex("1530477c7ab4113b59a4c18b076d11930f7da5d4a07f649bf54439d87d27e500fc8c25ebf8c92f6812cfc71c71c6d706", "1530477c7ab4113b59a4c18b076d11930f7da5d4a07f649bf54439d87d27e500fc8c25ebf8c92f6812cfc71c71c6d706"),
+		fp2FromHex("0", "5c759507e8e333ebb5b7a9a47d7ed8532c52d39fd3a042a88b58423c50ae15d5c2638e343d9c71c6238aaaaaaaa97be"),
+		fp2FromHex("11560bf17baa99bc32126fced787c88f984f87adf7ae0c7f9a208c6b4f20a4181472aaa9cb8d555526a9ffffffffc71c", "8ab05f8bdd54cde190937e76bc3e447cc27c3d6fbd7063fcd104635a790520c0a395554e5c6aaaa9354ffffffffe38f"),
+		fp2FromHex("124c9ad43b6cf79bfbf7043de3811ad0761b0f37a1e26286b0e977c69aa274524e79097a56dc4bd9e1b371c71c718b10", "0"),
+	}
+	isoYDen = []fp2{
+		fp2FromHex("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffa8fb", "1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffa8fb"),
+		fp2FromHex("0", "1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffa9d3"),
+		fp2FromHex("12", "1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaa99"),
+		fp2FromHex("1", "0"),
+	}
+)
+
+func reverseBytes(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+// expandMessageXmdReader is expand_message_xmd with SHA-256, reading the message from r.
+func expandMessageXmdReader(r io.Reader, dst []byte, lenInBytes int) ([]byte, error) {
+	if len(dst) > 255 {
+		return nil, errDSTTooLong
+	}
+	ell := (lenInBytes + sha256.Size - 1) / sha256.Size
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	h := sha256.New()
+	h.Write(make([]byte, sha256.BlockSize))
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	h.Write([]byte{byte(lenInBytes >> 8), byte(lenInBytes), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	uniform := make([]byte, 0, ell*sha256.Size)
+	bi := make([]byte, sha256.Size)
+	for i := 1; i <= ell; i++ {
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		uniform = append(uniform, bi...)
+	}
+	return uniform[:lenInBytes], nil
+}
+
+// mapToCurveSSWU maps u to the curve 3-isogenous to G2.
+func mapToCurveSSWU(u fp2) (fp2, fp2) {
+	zu2 := sswuZ.mul(u.square())
+	tv1 := zu2.square().add(zu2).inv()
+	var x1 fp2
+	if tv1.isZero() {
+		x1 = sswuB.mul(sswuZ.mul(sswuA).inv())
+	} else {
+		x1 = sswuB.neg().mul(sswuA.inv()).mul(tv1.add(newFp2(big.NewInt(1), new(big.Int))))
+	}
+	gx1 := x1.square().mul(x1).add(sswuA.mul(x1)).add(sswuB)
+	x, y := x1, fp2{}
+	if y1, ok := gx1.sqrt(); ok {
+		y = y1
+	} else {
+		x = zu2.mul(x1)
+		gx2 := x.square().mul(x).add(sswuA.mul(x)).add(sswuB)
+		// gx2 is a square whenever gx1 is not.
+		y, _ = gx2.sqrt()
+	}
+	if u.sgn0() != y.sgn0() {
+		y = y.neg()
+	}
+	return x, y
+}
+
+func evaluatePolynomial(coefficients []fp2, x fp2) fp2 {
+	result := coefficients[len(coefficients)-1]
+	for i := len(coefficients) - 2; i >= 0; i-- {
+		result = result.mul(x).add(coefficients[i])
+	}
+	return result
+}
+
+// isoMap maps a point of the isogenous curve to G2, returning nil for the point at infinity.
+func isoMap(x, y fp2) *blst.P2Affine {
+	xDen := evaluatePolynomial(isoXDen, x)
+	yDen := evaluatePolynomial(isoYDen, x)
+	if xDen.isZero() || yDen.isZero() {
+		return nil
+	}
+	xMapped := evaluatePolynomial(isoXNum, x).mul(xDen.inv())
+	yMapped := y.mul(evaluatePolynomial(isoYNum, x)).mul(yDen.inv())
+
+	var serialized [192]byte
+	xMapped.c1.FillBytes(serialized[0:48])
+	xMapped.c0.FillBytes(serialized[48:96])
+	yMapped.c1.FillBytes(serialized[96:144])
+	yMapped.c0.FillBytes(serialized[144:192])
+	return new(blst.P2Affine).Deserialize(serialized[:])
+}
+
+// hashToG2Reader is hash_to_curve for BLS12381G2_XMD:SHA-256_SSWU_RO_, reading the message from r.
+// It returns the same point as blst.HashToG2.
+func hashToG2Reader(r io.Reader, dst []byte) (*blst.P2, error) {
+	uniform, err := expandMessageXmdReader(r, dst, hashToFieldLength)
+	if err != nil {
+		return nil, err
+	}
+	point := new(blst.P2)
+	for i := 0; i < 2; i++ {
+		offset := i * 2 * hashToFieldL
+		u := newFp2(
+			new(big.Int).SetBytes(uniform[offset:offset+hashToFieldL]),
+			new(big.Int).SetBytes(uniform[offset+hashToFieldL:offset+2*hashToFieldL]),
+		)
+		if q := isoMap(mapToCurveSSWU(u)); q != nil {
+			point.AddAssign(q)
+		}
+	}
+	return point.MultAssign(g2CofactorLE), nil
+}
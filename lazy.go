@@ -0,0 +1,336 @@
+package bls
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// SignatureSource is a signature which may not be decoded yet, *Signature and *LazySignature satisfy it.
+type SignatureSource interface {
+	// Bytes returns the compressed signature.
+	Bytes() []byte
+	// Signature returns the decoded signature, or why it cannot be decoded.
+	Signature() (*Signature, error)
+}
+
+// rawSignature is a compressed signature decoded on every use, as done by the functions taking bytes.
+type rawSignature []byte
+
+func (r rawSignature) Bytes() []byte {
+	return r
+}
+
+func (r rawSignature) Signature() (*Signature, error) {
+	return NewSignatureFromBytes(r)
+}
+
+// missingSignature tells whether source is nil, including a nil *Signature or *LazySignature.
+func missingSignature(source SignatureSource) bool {
+	switch s := source.(type) {
+	case nil:
+		return true
+	case *Signature:
+		return s == nil
+	case *LazySignature:
+		return s == nil
+	}
+	return false
+}
+
+// LazySignature keeps a compressed signature and decodes it on first use, so that signatures dropped before
+// verification never pay for decompression and the subgroup check. The result of the decoding is kept, and it
+// is safe for concurrent use.
+type LazySignature struct {
+	raw [signatureLength]byte
+
+	mu        sync.Mutex
+	decoded   bool
+	signature *Signature
+	err       error
+}
+
+// NewLazySignature copies a 96 bytes long signature without decoding it.
+func NewLazySignature(b []byte) (*LazySignature, error) {
+	if len(b) != signatureLength {
+		return nil, fmt.Errorf("bls(signature): invalid signature length. should be %d", signatureLength)
+	}
+	l := new(LazySignature)
+	copy(l.raw[:], b)
+	return l, nil
+}
+
+// Bytes returns the compressed signature.
+func (l *LazySignature) Bytes() []byte {
+	return append([]byte{}, l.raw[:]...)
+}
+
+// Signature decodes the signature like NewSignatureFromBytes, only the first call does the work.
+func (l *LazySignature) Signature() (*Signature, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.decoded {
+		l.signature, l.err = NewSignatureFromBytes(l.raw[:])
+		l.decoded = true
+	}
+	return l.signature, l.err
+}
+
+// memoize keeps the result of a decoding done elsewhere, unless the signature was decoded meanwhile.
+func (l *LazySignature) memoize(signature *Signature, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.decoded {
+		l.signature, l.err = signature, err
+		l.decoded = true
+	}
+}
+
+func (l *LazySignature) memoized() (*Signature, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.signature, l.decoded, l.err
+}
+
+// Verify verify signature against one public key.
+func (l *LazySignature) Verify(msg []byte, pk PublicKey) (bool, error) {
+	signature, err := l.Signature()
+	if err != nil {
+		return false, err
+	}
+	return signature.Verify(msg, pk), nil
+}
+
+// VerifyAggregate verify signature against many public keys.
+func (l *LazySignature) VerifyAggregate(msg []byte, publicKeys []PublicKey) (bool, error) {
+	signature, err := l.Signature()
+	if err != nil {
+		return false, err
+	}
+	return signature.VerifyAggregate(msg, publicKeys), nil
+}
+
+// AggregateVerify verify signature against many public keys, each of which signed its own message.
+func (l *LazySignature) AggregateVerify(msgs [][]byte, publicKeys []PublicKey, enforceDistinct bool) (bool, error) {
+	signature, err := l.Signature()
+	if err != nil {
+		return false, err
+	}
+	return signature.AggregateVerify(msgs, publicKeys, enforceDistinct), nil
+}
+
+// decodeLazy decodes the signatures which were not decoded yet, subgroup checking them at once, and returns
+// every signature along with why it cannot be decoded. err is only set when ctx is done or the batch subgroup
+// check fails, nothing new is memoized then.
+func decodeLazy(ctx context.Context, sigs []*LazySignature) (signatures []*Signature, errs []error, err error) {
+	signatures = make([]*Signature, len(sigs))
+	errs = make([]error, len(sigs))
+	var (
+		pending []int
+		points  []*blst.P2Affine
+		encoded [][]byte
+	)
+	for i, l := range sigs {
+		if i%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+		}
+		var decoded bool
+		if signatures[i], decoded, errs[i] = l.memoized(); decoded {
+			continue
+		}
+		point := new(blst.P2Affine).Uncompress(l.raw[:])
+		if point == nil {
+			errs[i] = ErrDeserializeSignature
+			l.memoize(nil, errs[i])
+			continue
+		}
+		pending = append(pending, i)
+		points = append(points, point)
+		encoded = append(encoded, l.raw[:])
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	invalid, err := invalidG2(points, encoded)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, j := range invalid {
+		errs[pending[j]] = ErrNotGroupSignature
+	}
+	for j, i := range pending {
+		if errs[i] == nil {
+			signatures[i] = &Signature{affine: points[j]}
+		}
+		// Another goroutine may have decoded the signature meanwhile, both results are the same.
+		sigs[i].memoize(signatures[i], errs[i])
+	}
+	return signatures, errs, nil
+}
+
+// decodeLazySignatures decodes the signatures which were not decoded yet, subgroup checking them at once.
+// Errors tell the index of the first invalid signature.
+func decodeLazySignatures(ctx context.Context, sigs []*LazySignature) ([]*Signature, error) {
+	signatures, errs, err := decodeLazy(ctx, sigs)
+	if err != nil {
+		return nil, err
+	}
+	for i, err := range errs {
+		if err != nil {
+			return nil, errors.Wrapf(err, "signature %d", i)
+		}
+	}
+	return signatures, nil
+}
+
+// decodeLazySources decodes the lazy signatures among sources at once, so that decoding them one by one later
+// only reads the memoized result. Failures are left to the later decoding.
+func decodeLazySources(sources []SignatureSource) {
+	var sigs []*LazySignature
+	for _, source := range sources {
+		if l, ok := source.(*LazySignature); ok && l != nil {
+			sigs = append(sigs, l)
+		}
+	}
+	if len(sigs) > 1 {
+		_, _, _ = decodeLazy(context.Background(), sigs)
+	}
+}
+
+// lazyBytes returns the compressed signatures without copying them.
+func lazyBytes(sigs []*LazySignature) [][]byte {
+	encoded := make([][]byte, len(sigs))
+	for i, l := range sigs {
+		encoded[i] = l.raw[:]
+	}
+	return encoded
+}
+
+// AggregateLazySignatures is AggregateSignatures for lazy signatures, the ones not decoded yet are decoded at once.
+func AggregateLazySignatures(sigs []*LazySignature) (*Signature, error) {
+	return AggregateLazySignaturesContext(context.Background(), sigs)
+}
+
+// VerifyLazy is Verify for a lazy signature.
+func VerifyLazy(signature *LazySignature, msg []byte, publicKey []byte) (bool, error) {
+	return PopCiphersuite.VerifyLazy(signature, msg, publicKey)
+}
+
+// VerifyAggregateLazy is VerifyAggregate for a lazy signature.
+func VerifyAggregateLazy(signature *LazySignature, msg []byte, publicKeys [][]byte) (bool, error) {
+	return PopCiphersuite.VerifyAggregateLazy(signature, msg, publicKeys)
+}
+
+// AggregateVerifyLazy is AggregateVerify for a lazy signature.
+func AggregateVerifyLazy(signature *LazySignature, msgs [][]byte, publicKeys [][]byte, enforceDistinct bool) (bool, error) {
+	return PopCiphersuite.AggregateVerifyLazy(signature, msgs, publicKeys, enforceDistinct)
+}
+
+// VerifyMultipleLazySignatures is VerifyMultipleSignatures for lazy signatures, the ones not decoded yet are
+// decoded at once. Signatures which cannot be decoded make the batch invalid.
+func VerifyMultipleLazySignatures(sigs []*LazySignature, msgs [][]byte, pubKeys [][]byte) (bool, error) {
+	return PopCiphersuite.VerifyMultipleLazySignatures(sigs, msgs, pubKeys)
+}
+
+// VerifyLazy is Verify for a lazy signature.
+func (c *Ciphersuite) VerifyLazy(signature *LazySignature, msg []byte, publicKey []byte) (bool, error) {
+	return c.verifyContext(context.Background(), signature, msg, publicKey)
+}
+
+// VerifyAggregateLazy is VerifyAggregate for a lazy signature.
+func (c *Ciphersuite) VerifyAggregateLazy(signature *LazySignature, msg []byte, publicKeys [][]byte) (bool, error) {
+	return c.verifyAggregateContext(context.Background(), signature, msg, publicKeys)
+}
+
+// AggregateVerifyLazy is AggregateVerify for a lazy signature.
+func (c *Ciphersuite) AggregateVerifyLazy(signature *LazySignature, msgs [][]byte, publicKeys [][]byte, enforceDistinct bool) (bool, error) {
+	return c.aggregateVerify(signature, msgs, publicKeys, enforceDistinct)
+}
+
+// VerifyMultipleLazySignatures is VerifyMultipleSignatures for lazy signatures.
+func (c *Ciphersuite) VerifyMultipleLazySignatures(sigs []*LazySignature, msgs [][]byte, pubKeys [][]byte) (bool, error) {
+	var augs [][]byte
+	if c.scheme == schemeAug {
+		augs = pubKeys
+	}
+	return verifyMultipleLazySignatures(context.Background(), sigs, msgs, pubKeys, c.dst, augs)
+}
+
+// verifyMultipleLazySignatures is verifyMultipleSignatures for lazy signatures, abandoned once ctx is done.
+func verifyMultipleLazySignatures(ctx context.Context, sigs []*LazySignature, msgs [][]byte, pubKeys [][]byte, dst []byte, augs [][]byte) (bool, error) {
+	if len(sigs) == 0 || len(pubKeys) == 0 {
+		return false, nil
+	}
+	length := len(sigs)
+	if length != len(pubKeys) || length != len(msgs) {
+		return false, errors.Errorf("provided signatures, pubkeys and messages have differing lengths. S: %d, P: %d,M %d",
+			length, len(pubKeys), len(msgs))
+	}
+	return verifications.verifyRemaining(dst, lazyBytes(sigs), msgs, pubKeys, func(remaining []int) (bool, error) {
+		if remaining != nil {
+			sigs, msgs, pubKeys = pick(sigs, remaining), pick(msgs, remaining), pick(pubKeys, remaining)
+			if augs != nil {
+				augs = pick(augs, remaining)
+			}
+		}
+		publicKeys, err := decodePublicKeysContext(ctx, pubKeys, false)
+		if err != nil {
+			return false, err
+		}
+		signatures, errs, err := decodeLazy(ctx, sigs)
+		if err != nil {
+			return false, err
+		}
+		entries := make([]batchEntry, len(sigs))
+		for i := range entries {
+			if errs[i] != nil {
+				return false, nil
+			}
+			msg := msgs[i]
+			if augs != nil {
+				msg = append(append([]byte{}, augs[i]...), msg...)
+			}
+			entries[i] = batchEntry{signature: signatures[i].affine, publicKey: publicKeys[i], msg: msg, dst: dst}
+		}
+		return verifyBatchContext(ctx, entries)
+	})
+}
+
+// VerifyMultipleLazySignaturesBisect is VerifyMultipleSignaturesBisect for lazy signatures.
+func VerifyMultipleLazySignaturesBisect(sigs []*LazySignature, msgs [][]byte, pubKeys [][]byte) ([]int, error) {
+	length := len(sigs)
+	if length != len(pubKeys) || length != len(msgs) {
+		return nil, errors.Errorf("provided signatures, pubkeys and messages have differing lengths. S: %d, P: %d,M %d",
+			length, len(pubKeys), len(msgs))
+	}
+	return verifyBisect(lazyBytes(sigs), msgs, pubKeys, func(remaining []int) ([]batchEntry, []int, []int, error) {
+		if remaining != nil {
+			sigs, msgs, pubKeys = pick(sigs, remaining), pick(msgs, remaining), pick(pubKeys, remaining)
+		}
+		signatures, errs, err := decodeLazy(context.Background(), sigs)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		affines := make([]*blst.P2Affine, len(sigs))
+		for i, signature := range signatures {
+			if errs[i] == nil {
+				affines[i] = signature.affine
+			}
+		}
+		return decodeBatchKeys(affines, nil, msgs, pubKeys)
+	})
+}
+
+// VerifySameMessageLazySignatures is VerifySameMessageSignatures for lazy signatures.
+func VerifySameMessageLazySignatures(sigs []*LazySignature, msg []byte, pubKeys [][]byte) ([]int, error) {
+	msgs := make([][]byte, len(sigs))
+	for i := range msgs {
+		msgs[i] = msg
+	}
+	return VerifyMultipleLazySignaturesBisect(sigs, msgs, pubKeys)
+}
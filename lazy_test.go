@@ -0,0 +1,239 @@
+package bls_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func lazySignatures(t *testing.T, sigs [][]byte) []*bls.LazySignature {
+	lazies := make([]*bls.LazySignature, len(sigs))
+	for i, sig := range sigs {
+		var err error
+		lazies[i], err = bls.NewLazySignature(sig)
+		require.NoError(t, err)
+	}
+	return lazies
+}
+
+func TestLazySignature(t *testing.T) {
+	privateKey, err := bls.GenerateKey()
+	require.NoError(t, err)
+	msg := []byte("block")
+	sig := privateKey.Sign(msg).Bytes()
+
+	_, err = bls.NewLazySignature(sig[1:])
+	require.Error(t, err)
+
+	lazy, err := bls.NewLazySignature(sig)
+	require.NoError(t, err)
+	require.Equal(t, sig, lazy.Bytes())
+
+	var wg sync.WaitGroup
+	signatures := make([]*bls.Signature, 8)
+	for i := range signatures {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			signatures[i], _ = lazy.Signature()
+		}(i)
+	}
+	wg.Wait()
+	for _, signature := range signatures {
+		require.Same(t, signatures[0], signature)
+	}
+
+	valid, err := lazy.Verify(msg, privateKey.PublicKey())
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, err = lazy.VerifyAggregate(msg, []bls.PublicKey{privateKey.PublicKey()})
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, err = lazy.AggregateVerify([][]byte{msg}, []bls.PublicKey{privateKey.PublicKey()}, true)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	_, badSignature := outsideSubgroup(t)
+	lazy, err = bls.NewLazySignature(badSignature)
+	require.NoError(t, err)
+	_, err = lazy.Verify(msg, privateKey.PublicKey())
+	require.ErrorIs(t, err, bls.ErrNotGroupSignature)
+}
+
+func TestLazySignaturesBatch(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 100)
+	lazies := lazySignatures(t, sigs)
+	// Some signatures are decoded already, the others are decoded at once.
+	for _, lazy := range lazies[:10] {
+		_, err := lazy.Signature()
+		require.NoError(t, err)
+	}
+	valid, err := bls.VerifyMultipleLazySignatures(lazies, msgs, pubKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	aggregate, err := bls.AggregateLazySignatures(lazies)
+	require.NoError(t, err)
+	expected, err := bls.AggregateSignatures(sigs)
+	require.NoError(t, err)
+	require.Equal(t, expected, aggregate.Bytes())
+
+	_, badSignature := outsideSubgroup(t)
+	sigs[42] = badSignature
+	lazies = lazySignatures(t, sigs)
+	valid, err = bls.VerifyMultipleLazySignatures(lazies, msgs, pubKeys)
+	require.NoError(t, err)
+	require.False(t, valid)
+	_, err = lazies[42].Signature()
+	require.ErrorIs(t, err, bls.ErrNotGroupSignature)
+	_, err = lazies[41].Signature()
+	require.NoError(t, err)
+
+	_, err = bls.AggregateLazySignatures(lazies)
+	require.ErrorIs(t, err, bls.ErrNotGroupSignature)
+	require.Contains(t, err.Error(), "signature 42")
+	_, err = bls.AggregateLazySignatures(nil)
+	require.ErrorIs(t, err, bls.ErrNoSignaturesToAggregate)
+}
+
+func TestLazyEntryPoints(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 8)
+	lazies := lazySignatures(t, sigs)
+	_, badSignature := outsideSubgroup(t)
+	bad, err := bls.NewLazySignature(badSignature)
+	require.NoError(t, err)
+
+	valid, err := bls.VerifyLazy(lazies[0], msgs[0], pubKeys[0])
+	require.NoError(t, err)
+	require.True(t, valid)
+	_, err = bls.VerifyLazy(bad, msgs[0], pubKeys[0])
+	require.ErrorIs(t, err, bls.ErrNotGroupSignature)
+	valid, err = bls.VerifyAggregateLazy(lazies[1], msgs[1], [][]byte{pubKeys[1]})
+	require.NoError(t, err)
+	require.True(t, valid)
+	aggregate, err := bls.AggregateSignatures(sigs[:2])
+	require.NoError(t, err)
+	lazyAggregate, err := bls.NewLazySignature(aggregate)
+	require.NoError(t, err)
+	valid, err = bls.AggregateVerifyLazy(lazyAggregate, msgs[:2], pubKeys[:2], true)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	invalid, err := bls.VerifyMultipleLazySignaturesBisect(append(lazySignatures(t, sigs[:3]), bad), msgs[:4], pubKeys[:4])
+	require.NoError(t, err)
+	require.Equal(t, []int{3}, invalid)
+	invalid, err = bls.VerifySameMessageLazySignatures(lazies[:2], msgs[0], pubKeys[:2])
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, invalid)
+
+	set, err := bls.NewLazySignatureSet(lazies[2], msgs[2], [][]byte{pubKeys[2]})
+	require.NoError(t, err)
+	valid, err = bls.VerifySignatureSets([]*bls.SignatureSet{set, generateAggregateSet(t, 3, []byte("data"))})
+	require.NoError(t, err)
+	require.True(t, valid)
+	set, err = bls.NewLazySignatureSet(bad, msgs[2], [][]byte{pubKeys[2]})
+	require.NoError(t, err)
+	valid, err = bls.VerifySignatureSets([]*bls.SignatureSet{set})
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	v := bls.NewBatchVerifier(4, 10*time.Millisecond)
+	defer v.Close()
+	results := make(chan bool, 2)
+	for _, sig := range []*bls.LazySignature{lazies[3], bad} {
+		require.NoError(t, v.VerifyLazyLaneCallback(0, sig, msgs[3], pubKeys[3], func(valid bool, err error) {
+			require.NoError(t, err)
+			results <- valid
+		}))
+	}
+	require.ElementsMatch(t, []bool{true, false}, []bool{<-results, <-results})
+
+	publicKey, err := bls.NewPublicKeyFromBytes(pubKeys[4])
+	require.NoError(t, err)
+	require.True(t, bls.EthFastAggregateVerify([]bls.PublicKey{publicKey}, msgs[4], lazies[4]))
+	require.False(t, bls.EthFastAggregateVerify([]bls.PublicKey{publicKey}, msgs[4], bad))
+	require.False(t, bls.EthFastAggregateVerify([]bls.PublicKey{publicKey}, msgs[4], (*bls.LazySignature)(nil)))
+
+	var augSigs, augPubKeys [][]byte
+	for i := 0; i < 3; i++ {
+		privateKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		augSigs = append(augSigs, bls.AugCiphersuite.Sign(privateKey, msgs[i]).Bytes())
+		augPubKeys = append(augPubKeys, bls.CompressPublicKey(privateKey.PublicKey()))
+	}
+	valid, err = bls.AugCiphersuite.VerifyMultipleLazySignatures(lazySignatures(t, augSigs), msgs[:3], augPubKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, err = bls.AugCiphersuite.VerifyLazy(lazySignatures(t, augSigs)[0], msgs[0], augPubKeys[0])
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, err = bls.VerifyMultipleLazySignatures(lazySignatures(t, augSigs), msgs[:3], augPubKeys)
+	require.NoError(t, err)
+	require.False(t, valid)
+}
+
+func TestLazyContext(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 4)
+	lazies := lazySignatures(t, sigs)
+	ctx := context.Background()
+
+	valid, err := bls.VerifyLazyContext(ctx, lazies[0], msgs[0], pubKeys[0])
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, err = bls.VerifyAggregateLazyContext(ctx, lazies[1], msgs[1], [][]byte{pubKeys[1]})
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, err = bls.VerifyMultipleLazySignaturesContext(ctx, lazies, msgs, pubKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+	aggregate, err := bls.AggregateLazySignaturesContext(ctx, lazies)
+	require.NoError(t, err)
+	expected, err := bls.AggregateSignatures(sigs)
+	require.NoError(t, err)
+	require.Equal(t, expected, aggregate.Bytes())
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	fresh := lazySignatures(t, sigs)
+	_, err = bls.VerifyMultipleLazySignaturesContext(cancelled, fresh, msgs, pubKeys)
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = bls.AggregateLazySignaturesContext(cancelled, fresh)
+	require.ErrorIs(t, err, context.Canceled)
+	_, err = bls.VerifyLazyContext(cancelled, fresh[0], msgs[0], pubKeys[0])
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLazyVerificationCache(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 4)
+	bls.SetVerificationCacheSize(64)
+	defer bls.SetVerificationCacheSize(0)
+
+	// Lazy and compressed signatures share the cache.
+	valid, err := bls.VerifyMultipleLazySignatures(lazySignatures(t, sigs[:2]), msgs[:2], pubKeys[:2])
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, err = bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+	hits, misses := bls.VerificationCacheStats()
+	require.Equal(t, uint64(2), hits)
+	require.Equal(t, uint64(4), misses)
+
+	lazies := lazySignatures(t, sigs)
+	valid, err = bls.VerifyLazy(lazies[0], msgs[0], pubKeys[0])
+	require.NoError(t, err)
+	require.True(t, valid)
+	invalid, err := bls.VerifyMultipleLazySignaturesBisect(lazies, msgs, pubKeys)
+	require.NoError(t, err)
+	require.Empty(t, invalid)
+	valid, err = bls.VerifyMultipleLazySignaturesContext(context.Background(), lazies, msgs, pubKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+	hits, misses = bls.VerificationCacheStats()
+	require.Equal(t, uint64(2+1+4+4), hits)
+	require.Equal(t, uint64(4), misses)
+}
@@ -0,0 +1,76 @@
+package bls
+
+import (
+	"fmt"
+
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// Length of a BLS public key in the min-sig variant
+const minSigPublicKeyLength = 96
+
+// MinSigPublicKey is a public key of the minimal-signature-size variant, it lives in G2.
+type MinSigPublicKey *blst.P2Affine
+
+// NewMinSigPublicKey makes new empty min-sig Public Key.
+func NewMinSigPublicKey() MinSigPublicKey {
+	return new(blst.P2Affine)
+}
+
+func CompressMinSigPublicKey(p MinSigPublicKey) []byte {
+	return (*blst.P2Affine)(p).Compress()
+}
+
+// MinSigPublicKey retrieve the min-sig public key from the private key.
+func (p *PrivateKey) MinSigPublicKey() MinSigPublicKey {
+	return new(blst.P2Affine).From(p.key)
+}
+
+// NewMinSigPublicKeyFromBytes Derive new min-sig public key from a 96 long byte slice.
+func NewMinSigPublicKeyFromBytes(b []byte) (MinSigPublicKey, error) {
+	return newMinSigPublicKeyFromBytes(b, true)
+}
+
+func newMinSigPublicKeyFromBytes(b []byte, loadInCache bool) (MinSigPublicKey, error) {
+	if len(b) != minSigPublicKeyLength {
+		return nil, fmt.Errorf("bls(public): invalid key length. should be %d", minSigPublicKeyLength)
+	}
+
+	cachedAffine := minSigPkCache.getAffineFromCache(b)
+	if cachedAffine != nil {
+		return cachedAffine, nil
+	}
+
+	// Subgroup check NOT done when decompressing pubkey.
+	p := new(blst.P2Affine).Uncompress(b)
+	if p == nil {
+		return nil, ErrDeserializePublicKey
+	}
+	// Subgroup and infinity check
+	if !p.KeyValidate() {
+		return nil, ErrInfinitePublicKey
+	}
+	if loadInCache {
+		minSigPkCache.loadAffineIntoCache(b, p)
+	}
+
+	return p, nil
+}
+
+func AggregateMinSigPublicKeys(pubs [][]byte) ([]byte, error) {
+	if len(pubs) == 0 {
+		return nil, ErrNoPublicKeysToAggregate
+	}
+	agg := new(blst.P2Aggregate)
+	mulP2 := make([]*blst.P2Affine, 0, len(pubs))
+	for _, pubkey := range pubs {
+		pubKeyObj, err := NewMinSigPublicKeyFromBytes(pubkey)
+		if err != nil {
+			return nil, err
+		}
+		mulP2 = append(mulP2, pubKeyObj)
+	}
+	// No group check needed here since it is done in NewMinSigPublicKeyFromBytes
+	agg.Aggregate(mulP2, false)
+	return agg.ToAffine().Compress(), nil
+}
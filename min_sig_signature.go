@@ -0,0 +1,248 @@
+package bls
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pkg/errors"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// InfiniteMinSigSignature represents an infinite min-sig signature (G1 Point at Infinity).
+var InfiniteMinSigSignature = [48]byte{0xC0}
+
+// Length of a BLS signature in the min-sig variant
+const minSigSignatureLength = 48
+
+// MinSigSignature is a signature of the minimal-signature-size variant, it lives in G1.
+type MinSigSignature struct {
+	affine *blst.P1Affine
+}
+
+// NewMinSigSignature creates a new empty min-sig signature.
+func NewMinSigSignature() *MinSigSignature {
+	return &MinSigSignature{affine: new(blst.P1Affine)}
+}
+
+// NewMinSigSignatureFromBytes creates a new min-sig signature from a 48 bytes long slice.
+func NewMinSigSignatureFromBytes(b []byte) (*MinSigSignature, error) {
+	if len(b) != minSigSignatureLength {
+		return nil, fmt.Errorf("bls(signature): invalid signature length. should be %d", minSigSignatureLength)
+	}
+	signature := new(blst.P1Affine).Uncompress(b)
+	if signature == nil {
+		return nil, ErrDeserializeSignature
+	}
+	// Group check signature. Do not check for infinity since an aggregated signature
+	// could be infinite.
+	if !signature.SigValidate(false) {
+		return nil, ErrNotGroupSignature
+	}
+	return &MinSigSignature{affine: signature}, nil
+}
+
+// SignMinSig a message with BLS, the signature lives in G1.
+func (p *PrivateKey) SignMinSig(msg []byte) *MinSigSignature {
+	return PopCiphersuite.SignMinSig(p, msg)
+}
+
+func (s MinSigSignature) Bytes() []byte {
+	return s.affine.Compress()
+}
+
+// Verify verify signature against one public key.
+func (s MinSigSignature) Verify(msg []byte, pk MinSigPublicKey) bool {
+	return PopCiphersuite.VerifyMinSigSignature(&s, msg, pk)
+}
+
+// VerifyAggregate verify signature against many public keys.
+func (s MinSigSignature) VerifyAggregate(msg []byte, publicKeys []MinSigPublicKey) bool {
+	valid, _ := PopCiphersuite.VerifyAggregateMinSigSignature(&s, msg, publicKeys)
+	return valid
+}
+
+// VerifyMinSig verify min-sig signature against one public key.
+func VerifyMinSig(signature []byte, msg []byte, publicKeyBytes []byte) (bool, error) {
+	return PopCiphersuite.VerifyMinSig(signature, msg, publicKeyBytes)
+}
+
+// VerifyAggregateMinSig verify min-sig signature against many public keys.
+func VerifyAggregateMinSig(signature []byte, msg []byte, publicKeysBytes [][]byte) (bool, error) {
+	return PopCiphersuite.VerifyAggregateMinSig(signature, msg, publicKeysBytes)
+}
+
+// VerifyMultipleSignaturesMinSig is VerifyMultipleSignatures for the min-sig variant.
+func VerifyMultipleSignaturesMinSig(sigs [][]byte, msgs [][]byte, pubKeys [][]byte) (bool, error) {
+	return PopCiphersuite.VerifyMultipleSignaturesMinSig(sigs, msgs, pubKeys)
+}
+
+func AggregateMinSigSignatures(sigs [][]byte) ([]byte, error) {
+	if len(sigs) == 0 {
+		return nil, ErrNoSignaturesToAggregate
+	}
+
+	agg := new(blst.P1Aggregate)
+	mulP1 := make([]*blst.P1Affine, 0, len(sigs))
+	for _, sig := range sigs {
+		sigObj, err := NewMinSigSignatureFromBytes(sig)
+		if err != nil {
+			return nil, err
+		}
+		mulP1 = append(mulP1, sigObj.affine)
+	}
+	// No group check needed here since it is done in NewMinSigSignatureFromBytes
+	agg.Aggregate(mulP1, false)
+	return agg.ToAffine().Compress(), nil
+}
+
+// minSigAugmentation returns the prefix of the message signed by publicKey.
+func (c *Ciphersuite) minSigAugmentation(publicKey MinSigPublicKey) []byte {
+	if c.scheme != schemeAug {
+		return nil
+	}
+	return CompressMinSigPublicKey(publicKey)
+}
+
+// SignMinSig a message with BLS, the signature lives in G1.
+func (c *Ciphersuite) SignMinSig(p *PrivateKey, msg []byte) *MinSigSignature {
+	signature := new(blst.P1Affine).Sign(p.key, msg, c.minSigDST, c.minSigAugmentation(p.MinSigPublicKey()))
+	return &MinSigSignature{affine: signature}
+}
+
+// VerifyMinSigSignature verify min-sig signature against one public key.
+func (c *Ciphersuite) VerifyMinSigSignature(s *MinSigSignature, msg []byte, pk MinSigPublicKey) bool {
+	return s.affine.Verify(false, pk, false, msg, c.minSigDST, c.minSigAugmentation(pk))
+}
+
+// VerifyAggregateMinSigSignature verify min-sig signature against many public keys which signed the same message.
+func (c *Ciphersuite) VerifyAggregateMinSigSignature(s *MinSigSignature, msg []byte, publicKeys []MinSigPublicKey) (bool, error) {
+	affines := make([]*blst.P2Affine, 0, len(publicKeys))
+	for _, publicKey := range publicKeys {
+		affines = append(affines, publicKey)
+	}
+	switch c.scheme {
+	case schemeBasic:
+		// The same message signed twice is not distinct.
+		if len(publicKeys) > 1 {
+			return false, ErrMessagesNotDistinct
+		}
+		msgs := make([]blst.Message, len(affines))
+		for i := range msgs {
+			msgs[i] = msg
+		}
+		return s.affine.AggregateVerify(true, affines, false, msgs, c.minSigDST), nil
+	case schemeAug:
+		msgs := make([]blst.Message, len(affines))
+		augs := make([][]byte, len(affines))
+		for i, publicKey := range publicKeys {
+			msgs[i] = msg
+			augs[i] = c.minSigAugmentation(publicKey)
+		}
+		return s.affine.AggregateVerify(true, affines, false, msgs, c.minSigDST, augs), nil
+	default:
+		return s.affine.FastAggregateVerify(true, affines, msg, c.minSigDST), nil
+	}
+}
+
+// VerifyMinSig verify min-sig signature against one public key.
+func (c *Ciphersuite) VerifyMinSig(signature []byte, msg []byte, publicKeyBytes []byte) (bool, error) {
+	sig, err := NewMinSigSignatureFromBytes(signature)
+	if err != nil {
+		return false, err
+	}
+
+	publicKey, err := NewMinSigPublicKeyFromBytes(publicKeyBytes)
+	if err != nil {
+		return false, err
+	}
+
+	return c.VerifyMinSigSignature(sig, msg, publicKey), nil
+}
+
+// VerifyAggregateMinSig verify min-sig signature against many public keys.
+func (c *Ciphersuite) VerifyAggregateMinSig(signature []byte, msg []byte, publicKeysBytes [][]byte) (bool, error) {
+	if c.scheme == schemePop && len(publicKeysBytes) == 0 && bytes.Equal(InfiniteMinSigSignature[:], signature) {
+		return true, nil
+	}
+	sig, err := NewMinSigSignatureFromBytes(signature)
+	if err != nil {
+		return false, err
+	}
+
+	publicKeys := []MinSigPublicKey{}
+	for _, publicKey := range publicKeysBytes {
+		key, err := NewMinSigPublicKeyFromBytes(publicKey)
+		if err != nil {
+			return false, err
+		}
+		publicKeys = append(publicKeys, key)
+	}
+
+	return c.VerifyAggregateMinSigSignature(sig, msg, publicKeys)
+}
+
+// VerifyMultipleSignaturesMinSig is VerifyMultipleSignatures for the min-sig variant.
+func (c *Ciphersuite) VerifyMultipleSignaturesMinSig(sigs [][]byte, msgs [][]byte, pubKeys [][]byte) (bool, error) {
+	if len(sigs) == 0 || len(pubKeys) == 0 {
+		return false, nil
+	}
+	rawSigs := new(blst.P1Affine).BatchUncompress(sigs)
+
+	length := len(sigs)
+	if length != len(pubKeys) || length != len(msgs) {
+		return false, errors.Errorf("provided signatures, pubkeys and messages have differing lengths. S: %d, P: %d,M %d",
+			length, len(pubKeys), len(msgs))
+	}
+	mulP2Aff := make([]*blst.P2Affine, length)
+	rawMsgs := make([]blst.Message, length)
+
+	for i := 0; i < length; i++ {
+		pk, err := newMinSigPublicKeyFromBytes(pubKeys[i], false)
+		if err != nil {
+			return false, err
+		}
+		mulP2Aff[i] = pk
+		rawMsgs[i] = msgs[i][:]
+	}
+	var augs [][]byte
+	if c.scheme == schemeAug {
+		augs = pubKeys
+	}
+	scalars, bits, err := batchScalars(length, bytesTranscript(sigs, msgs, pubKeys, c.minSigDST, augs))
+	if err != nil {
+		return false, err
+	}
+	if deterministicBatchRandomness() {
+		pairing, ok := minSigPairing(rawSigs, mulP2Aff, msgs, c.minSigDST, augs, scalars, bits)
+		return ok && blst.PairingFinalVerify(pairing), nil
+	}
+	randFunc := scalarsRandFunc(scalars, bits)
+	dummySig := new(blst.P1Affine)
+
+	// Validate signatures since we uncompress them here. Public keys should already be validated.
+	if augs != nil {
+		return dummySig.MultipleAggregateVerify(rawSigs, true, mulP2Aff, false, rawMsgs, c.minSigDST, randFunc, bits, augs), nil
+	}
+	return dummySig.MultipleAggregateVerify(rawSigs, true, mulP2Aff, false, rawMsgs, c.minSigDST, randFunc, bits), nil
+}
+
+// minSigPairing aggregates the pairings of a min-sig batch on the calling goroutine, the signature of index i
+// being weighted by the scalar of index i. It is false if a signature is missing or not in the group.
+func minSigPairing(sigs []*blst.P1Affine, pubKeys []*blst.P2Affine, msgs [][]byte, dst []byte, augs [][]byte, scalars []byte, bits int) (blst.Pairing, bool) {
+	if len(sigs) != len(pubKeys) {
+		return nil, false
+	}
+	pairing := blst.PairingCtx(true, dst)
+	for i := range sigs {
+		var aug []byte
+		if augs != nil {
+			aug = augs[i]
+		}
+		// blst returns 0, BLST_SUCCESS, once the pairing is aggregated.
+		if blst.PairingMulNAggregatePkInG2(pairing, pubKeys[i], false, sigs[i], true, entryScalar(scalars, bits, i), bits, msgs[i], aug) != 0 {
+			return nil, false
+		}
+	}
+	blst.PairingCommit(pairing)
+	return pairing, true
+}
@@ -0,0 +1,219 @@
+package bls_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+func convertHexToMinSigPublicKey(h string) []byte {
+	b, err := hex.DecodeString(h)
+	if err != nil {
+		panic(err)
+	}
+	pk := make([]byte, 96)
+	copy(pk, b)
+	return pk
+}
+
+func convertHexToMinSigSignature(h string) []byte {
+	b, err := hex.DecodeString(h)
+	if err != nil {
+		panic(err)
+	}
+	sig := make([]byte, 48)
+	copy(sig, b)
+	return sig
+}
+
+// referenceMinSigSign is referenceSign with messages hashed to G1.
+func referenceMinSigSign(privateKey []byte, msg []byte, dst []byte) []byte {
+	return blst.HashToG1(msg, dst).Mult(new(blst.Scalar).Deserialize(privateKey)).ToAffine().Compress()
+}
+
+// referenceMinSigPublicKey computes sk * G2 straight from the curve primitives.
+func referenceMinSigPublicKey(privateKey []byte) []byte {
+	return blst.P2Generator().Mult(new(blst.Scalar).Deserialize(privateKey)).ToAffine().Compress()
+}
+
+// G2 public keys of ciphersuitePrivateKeys. The keys and the signatures below are not the IETF draft vectors:
+// they were computed with github.com/kilic/bls12-381, an implementation independent of blst, following
+// draft-irtf-cfrg-bls-signature-05 with the BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_ DSTs of section 4.2, and
+// TestMinSigReference recomputes them from that definition.
+var minSigPublicKeys = []string{
+	"ac400b70f6f8cd35648f5c126cce5417f3be4d8eefbd42ceb4286a14df7e03135313fe5845e3a575faab3e8b949d248814856c22d8cdb2967c720e963eedc999e738373b14172f06fc915769d3cc5ab7ae0a1b9c38f48b5585fb09d4bd2733bb",
+	"a4b8f49c3bac0247a09487049492b0ed99cf90c56263141daa35f011330d3ced3f3ad78d252c51a3bb42fc7d8f1825940bc2357c6782bbb6a078d9e171fc7a81f7bd8ca73eb485e76317359908bb09bd372fd362a637512a9d48019b383e5489",
+	"b0b39dda41e997feedd65253bd98bb1a150584dc23aca4c16d967b725ce86736ccdd33845de3058aafda88485750759908fd5505c6c3daf58fde81bdadbbefbc625dd9885faef3fca406a086f743d5eab6b6cb36b1984cbf08c6a4effcb3018d",
+}
+
+// Min-sig signatures of ciphersuiteMessages[i] by ciphersuitePrivateKeys[i] for each suite.
+var minSigTestVectors = []struct {
+	name        string
+	ciphersuite *bls.Ciphersuite
+	signatures  []string
+	// Aggregate of all the signatures of ciphersuiteMessages[1].
+	sameMessageAggregate string
+}{
+	{
+		name:        "basic",
+		ciphersuite: bls.BasicCiphersuite,
+		signatures: []string{
+			"91137957a775ade818b445ba63d00c3edaf7d8d88aad7e1f80df864a8d8390ccb58b71b876edf37a565dc43abe52eb00",
+			"ab30f1e13614a58aa9d3fb00781e8e3b4657d5683e277ab4fe74d88ca3724cd1486576405e5fa9b6194ffbc8409e46c1",
+			"b3797f5645661d356202ee6229902856f23c508a962d660626fa1a4c83d92e352f4fcd661a9917860844e35170af6f44",
+		},
+		sameMessageAggregate: "83c13f008458edc7e4e748f6f4fbad787ffcdb052423179a66727524b8b7f36da2c4e10e596183b99c3a2125f8c35392",
+	},
+	{
+		name:        "aug",
+		ciphersuite: bls.AugCiphersuite,
+		signatures: []string{
+			"ab1499fb74386ea5299481d609e81f92bb59281e47e6663215fd8a3399185580eb4667f280f533f92bb0cac6cc9c70a5",
+			"96e77076b3f3adb5e60969fc3cda8424a388512f12ba82fcb3f18b0bb871a7dd33b8357ba6cae1d95615c3fdb2a9ebf6",
+			"b3a1abb012da4b36c606cacd65990445478be5c222afad26cc454854d78f25a3abd55072ee740466cbc156da530f5eb8",
+		},
+		sameMessageAggregate: "80f20d78aa587450211db09a30037addf6dcba2e0fc08402f5290e26073632a7bc2124ad2d1446536294b36bf38a8f2d",
+	},
+	{
+		name:        "pop",
+		ciphersuite: bls.PopCiphersuite,
+		signatures: []string{
+			"950998b098aeab7dddcef4916123247ae9f48ca4f7f0df3a487d244c26af107e4de324bd1181554122cfb251ed0b213f",
+			"8743502263ab1b477d44100af009889250b40425e5c4b950ebc830d819eb02fd8118bc7615c22cc7dc1b35f2d742a8f8",
+			"992d1d66d89f98903a46bb8dd18e90233b626f718ce22f3189964734146fd1c14a0224187921d32b9f06ae5943c5853c",
+		},
+		sameMessageAggregate: "b4d50e48a620e072b1e3140eb77a7e4bbb8f4df37314c25f1392f49ad0304c71beb3c3df49d5482adfc3525b730c0e86",
+	},
+}
+
+func TestMinSigReference(t *testing.T) {
+	dsts := map[string][]byte{
+		"basic": []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_NUL_"),
+		"aug":   []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_AUG_"),
+		"pop":   []byte("BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_POP_"),
+	}
+	for i, publicKey := range minSigPublicKeys {
+		require.Equal(t, convertHexToMinSigPublicKey(publicKey), referenceMinSigPublicKey(convertHexToPrivateKey(ciphersuitePrivateKeys[i])))
+	}
+	for _, v := range minSigTestVectors {
+		t.Run(v.name, func(t *testing.T) {
+			// The AUG suite signs the public key followed by the message.
+			message := func(publicKey []byte, msg string) []byte {
+				if v.name == "aug" {
+					return append(append([]byte{}, publicKey...), convertHexToMessage(msg)...)
+				}
+				return convertHexToMessage(msg)
+			}
+			aggregate := new(blst.P1Aggregate)
+			for i, signature := range v.signatures {
+				privateKey := convertHexToPrivateKey(ciphersuitePrivateKeys[i])
+				publicKey := convertHexToMinSigPublicKey(minSigPublicKeys[i])
+				require.Equal(t, convertHexToMinSigSignature(signature), referenceMinSigSign(privateKey, message(publicKey, ciphersuiteMessages[i]), dsts[v.name]))
+				aggregate.Add(new(blst.P1Affine).Uncompress(referenceMinSigSign(privateKey, message(publicKey, ciphersuiteMessages[1]), dsts[v.name])), false)
+			}
+			require.Equal(t, convertHexToMinSigSignature(v.sameMessageAggregate), aggregate.ToAffine().Compress())
+		})
+	}
+}
+
+func TestMinSigSign(t *testing.T) {
+	for _, v := range minSigTestVectors {
+		t.Run(v.name, func(t *testing.T) {
+			for i, signature := range v.signatures {
+				privateKey, err := bls.NewPrivateKeyFromBytes(convertHexToPrivateKey(ciphersuitePrivateKeys[i]))
+				require.NoError(t, err)
+				require.Equal(t, convertHexToMinSigPublicKey(minSigPublicKeys[i]), bls.CompressMinSigPublicKey(privateKey.MinSigPublicKey()))
+				msg := convertHexToMessage(ciphersuiteMessages[i])
+				sig := v.ciphersuite.SignMinSig(privateKey, msg)
+				require.Equal(t, convertHexToMinSigSignature(signature), sig.Bytes())
+				require.True(t, v.ciphersuite.VerifyMinSigSignature(sig, msg, privateKey.MinSigPublicKey()))
+
+				valid, err := v.ciphersuite.VerifyMinSig(convertHexToMinSigSignature(signature), msg, convertHexToMinSigPublicKey(minSigPublicKeys[i]))
+				require.NoError(t, err)
+				require.True(t, valid)
+			}
+		})
+	}
+}
+
+func TestMinSigVerifyAggregate(t *testing.T) {
+	publicKeys := [][]byte{
+		convertHexToMinSigPublicKey(minSigPublicKeys[0]),
+		convertHexToMinSigPublicKey(minSigPublicKeys[1]),
+		convertHexToMinSigPublicKey(minSigPublicKeys[2]),
+	}
+	msg := convertHexToMessage(ciphersuiteMessages[1])
+
+	valid, err := bls.VerifyAggregateMinSig(convertHexToMinSigSignature(minSigTestVectors[2].sameMessageAggregate), msg, publicKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	valid, err = bls.AugCiphersuite.VerifyAggregateMinSig(convertHexToMinSigSignature(minSigTestVectors[1].sameMessageAggregate), msg, publicKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	_, err = bls.BasicCiphersuite.VerifyAggregateMinSig(convertHexToMinSigSignature(minSigTestVectors[0].sameMessageAggregate), msg, publicKeys)
+	require.ErrorIs(t, err, bls.ErrMessagesNotDistinct)
+
+	valid, err = bls.VerifyAggregateMinSig(bls.InfiniteMinSigSignature[:], msg, nil)
+	require.NoError(t, err)
+	require.True(t, valid)
+}
+
+func TestMinSigVerifyMultipleSignatures(t *testing.T) {
+	for _, v := range minSigTestVectors {
+		t.Run(v.name, func(t *testing.T) {
+			sigs := make([][]byte, len(v.signatures))
+			msgs := make([][]byte, len(v.signatures))
+			publicKeys := make([][]byte, len(v.signatures))
+			for i, signature := range v.signatures {
+				sigs[i] = convertHexToMinSigSignature(signature)
+				msgs[i] = convertHexToMessage(ciphersuiteMessages[i])
+				publicKeys[i] = convertHexToMinSigPublicKey(minSigPublicKeys[i])
+			}
+			valid, err := v.ciphersuite.VerifyMultipleSignaturesMinSig(sigs, msgs, publicKeys)
+			require.NoError(t, err)
+			require.True(t, valid)
+
+			sigs[0], sigs[1] = sigs[1], sigs[0]
+			valid, err = v.ciphersuite.VerifyMultipleSignaturesMinSig(sigs, msgs, publicKeys)
+			require.NoError(t, err)
+			require.False(t, valid)
+		})
+	}
+}
+
+func TestMinSigAggregateSignatures(t *testing.T) {
+	msg := convertHexToMessage("5656565656565656565656565656565656565656565656565656565656565656")
+
+	privateKey1, err := bls.GenerateKey()
+	require.NoError(t, err)
+	privateKey2, err := bls.GenerateKey()
+	require.NoError(t, err)
+
+	aggregatedPublicKey, err := bls.AggregateMinSigPublicKeys([][]byte{bls.CompressMinSigPublicKey(privateKey1.MinSigPublicKey()), bls.CompressMinSigPublicKey(privateKey2.MinSigPublicKey())})
+	require.NoError(t, err)
+	aggregatedSignature, err := bls.AggregateMinSigSignatures([][]byte{privateKey1.SignMinSig(msg).Bytes(), privateKey2.SignMinSig(msg).Bytes()})
+	require.NoError(t, err)
+
+	valid, err := bls.VerifyMinSig(aggregatedSignature, msg, aggregatedPublicKey)
+	require.NoError(t, err)
+	require.True(t, valid)
+}
+
+func TestMinSigCache(t *testing.T) {
+	bls.SetEnabledCaching(true)
+	defer bls.SetEnabledCaching(false)
+	defer bls.ClearCache()
+	for _, publicKey := range minSigPublicKeys {
+		require.NoError(t, bls.LoadMinSigPublicKeyIntoCache(convertHexToMinSigPublicKey(publicKey), true))
+	}
+	require.ErrorIs(t, bls.LoadMinSigPublicKeyIntoCache(convertHexToPublicKey(ciphersuitePublicKeys[0]), true), bls.ErrDeserializePublicKey)
+
+	valid, err := bls.VerifyMinSig(convertHexToMinSigSignature(minSigTestVectors[2].signatures[0]), convertHexToMessage(ciphersuiteMessages[0]), convertHexToMinSigPublicKey(minSigPublicKeys[0]))
+	require.NoError(t, err)
+	require.True(t, valid)
+}
@@ -0,0 +1,135 @@
+package bls_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelVerifyMultipleSignatures(t *testing.T) {
+	bls.SetVerifyWorkers(3)
+	defer bls.SetVerifyWorkers(0)
+
+	for _, invalid := range [][]int{nil, {0}, {40}, {7, 8, 33}} {
+		sigs, msgs, pubKeys := generateBatch(t, 41, invalid...)
+		valid, err := bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+		require.NoError(t, err)
+		require.Equal(t, invalid == nil, valid)
+
+		found, err := bls.VerifyMultipleSignaturesBisect(sigs, msgs, pubKeys)
+		require.NoError(t, err)
+		if invalid == nil {
+			require.Empty(t, found)
+		} else {
+			require.Equal(t, invalid, found)
+		}
+	}
+
+	sigs, msgs, pubKeys := generateBatch(t, 41)
+	sigs[20] = make([]byte, 96)
+	valid, err := bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.False(t, valid)
+}
+
+func TestParallelCiphersuiteVerifyMultipleSignatures(t *testing.T) {
+	bls.SetVerifyWorkers(3)
+	defer bls.SetVerifyWorkers(0)
+
+	for _, ciphersuite := range []*bls.Ciphersuite{bls.BasicCiphersuite, bls.AugCiphersuite, bls.PopCiphersuite} {
+		var sigs, msgs, pubKeys [][]byte
+		for i := 0; i < 24; i++ {
+			privateKey, err := bls.GenerateKey()
+			require.NoError(t, err)
+			msg := []byte(fmt.Sprintf("message %d", i))
+			sigs = append(sigs, ciphersuite.Sign(privateKey, msg).Bytes())
+			msgs = append(msgs, msg)
+			pubKeys = append(pubKeys, bls.CompressPublicKey(privateKey.PublicKey()))
+		}
+		valid, err := ciphersuite.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+		require.NoError(t, err)
+		require.True(t, valid)
+
+		sigs[3], sigs[17] = sigs[17], sigs[3]
+		valid, err = ciphersuite.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+		require.NoError(t, err)
+		require.False(t, valid)
+	}
+}
+
+func TestParallelVerifySignatureSets(t *testing.T) {
+	bls.SetVerifyWorkers(2)
+	defer bls.SetVerifyWorkers(0)
+
+	var sets []*bls.SignatureSet
+	for i := 0; i < 4; i++ {
+		sets = append(sets, generateBlockSets(t)...)
+	}
+	require.True(t, verifySignatureSets(t, sets))
+	tampered := *sets[11]
+	tampered.Message = []byte("tampered")
+	sets[11] = &tampered
+	require.False(t, verifySignatureSets(t, sets))
+}
+
+func TestParallelChunks(t *testing.T) {
+	defer bls.SetVerifyWorkers(0)
+	sigs, msgs, pubKeys := generateBatch(t, 81)
+	// Splitting n entries into chunks of ceil(n/workers) may leave workers without entries.
+	for _, n := range []int{1, 9, 17, 33, 81} {
+		for workers := 1; workers <= 11; workers++ {
+			bls.SetVerifyWorkers(workers)
+			valid, err := bls.VerifyMultipleSignatures(sigs[:n], msgs[:n], pubKeys[:n])
+			require.NoError(t, err, "n=%d workers=%d", n, workers)
+			require.True(t, valid, "n=%d workers=%d", n, workers)
+			valid, err = bls.VerifyMultipleSignaturesContext(context.Background(), sigs[:n], msgs[:n], pubKeys[:n])
+			require.NoError(t, err, "n=%d workers=%d", n, workers)
+			require.True(t, valid, "n=%d workers=%d", n, workers)
+		}
+	}
+}
+
+func TestSetVerifyWorkersConcurrently(t *testing.T) {
+	defer bls.SetVerifyWorkers(0)
+	sigs, msgs, pubKeys := generateBatch(t, 40)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 5; i++ {
+				valid, err := bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+				require.NoError(t, err)
+				require.True(t, valid)
+			}
+		}()
+	}
+	for _, workers := range []int{2, 0, 1, 3, 0} {
+		bls.SetVerifyWorkers(workers)
+	}
+	wg.Wait()
+}
+
+// Every row verifies through the same code path, with at most workers cores.
+func BenchmarkParallelVerifyMultipleSignatures(b *testing.B) {
+	sigs, msgs, pubKeys := generateBatch(b, 512)
+	for workers := 1; ; workers *= 2 {
+		workers = min(workers, runtime.GOMAXPROCS(0))
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			bls.SetVerifyWorkers(workers)
+			defer bls.SetVerifyWorkers(0)
+			for i := 0; i < b.N; i++ {
+				bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+			}
+		})
+		if workers == runtime.GOMAXPROCS(0) {
+			break
+		}
+	}
+}
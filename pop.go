@@ -0,0 +1,52 @@
+package bls
+
+import (
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// Proof of possession uses its own DST so that a proof can never be replayed as a signature.
+var popCurve = []byte("BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+// PopProve generates a proof of possession for the private key.
+func (p *PrivateKey) PopProve() *Signature {
+	publicKey := new(blst.P1Affine).From(p.key).Compress()
+	proof := new(blst.P2Affine).Sign(p.key, publicKey, popCurve)
+	return &Signature{affine: proof}
+}
+
+// PopVerify verify proof of possession against one public key.
+func (s Signature) PopVerify(pk PublicKey) bool {
+	publicKey := (*blst.P1Affine)(pk)
+	// The public key is validated here, a proof is worthless for a key outside of the group.
+	return s.affine.Verify(true, publicKey, true, publicKey.Compress(), popCurve)
+}
+
+// PopVerify verify proof of possession against one public key.
+func PopVerify(proof []byte, publicKeyBytes []byte) (bool, error) {
+	sig, err := NewSignatureFromBytes(proof)
+	if err != nil {
+		return false, err
+	}
+
+	publicKey, err := newPublicKeyFromBytes(publicKeyBytes, false)
+	if err != nil {
+		return false, err
+	}
+
+	return sig.PopVerify(publicKey), nil
+}
+
+// LoadPublicKeyIntoCacheWithPop loads a public key into the cache only if its proof of possession verifies.
+func LoadPublicKeyIntoCacheWithPop(publicKey []byte, proof []byte) error {
+	if !enabledCache {
+		return ErrCacheNotEnabled
+	}
+	valid, err := PopVerify(proof, publicKey)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return ErrInvalidPop
+	}
+	return loadPublicKeyIntoCache(publicKey, false)
+}
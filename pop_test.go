@@ -0,0 +1,81 @@
+package bls_test
+
+import (
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+// Proofs of possession for the consensus-spec test keys, under BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_.
+// They are not the IETF draft vectors: the proofs were computed with github.com/kilic/bls12-381, an
+// implementation independent of blst, as sk * hash_to_curve(pk) following draft-irtf-cfrg-bls-signature-05
+// section 3.3.2, and TestPopReference recomputes them from that definition.
+var popTestVectors = []struct {
+	privateKey string
+	publicKey  string
+	proof      string
+}{
+	{
+		privateKey: "263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3",
+		publicKey:  "a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a",
+		proof:      "b803eb0ed93ea10224a73b6b9c725796be9f5fefd215ef7a5b97234cc956cf6870db6127b7e4d824ec62276078e787db05584ce1adbf076bc0808ca0f15b73d59060254b25393d95dfc7abe3cda566842aaedf50bbb062aae1bbb6ef3b1f77e1",
+	},
+	{
+		privateKey: "47b8192d77bf871b62e87859d653922725724a5c031afeabc60bcef5ff665138",
+		publicKey:  "b301803f8b5ac4a1133581fc676dfedc60d891dd5fa99028805e5ea5b08d3491af75d0707adab3b70c6a6a580217bf81",
+		proof:      "88bb31b27eae23038e14f9d9d1b628a39f5881b5278c3c6f0249f81ba0deb1f68aa5f8847854d6554051aa810fdf1cdb02df4af7a5647b1aa4afb60ec6d446ee17af24a8a50876ffdaf9bf475038ec5f8ebeda1c1c6a3220293e23b13a9a5d26",
+	},
+	{
+		privateKey: "328388aff0d4a5b7dc9205abd374e7e98f3cd9f3418edb4eafda5fb16473d216",
+		publicKey:  "b53d21a4cfd562c469cc81514d4ce5a6b577d8403d32a394dc265dd190b47fa9f829fdd7963afdf972e5e77854051f6f",
+		proof:      "88873ea58f5017a33facc9bf04efaf5e2f34f7bc9ce564d0481dd469326c04ef43552f50e99de8a13315dcd37a4fb9ef036d1a54e5febf5d20b6aa488f3e3c917e6a96ce6461f609ec7e0a1fd8950380922e46c3654fa7542436603f833462da",
+	},
+}
+
+func TestPopProve(t *testing.T) {
+	for _, v := range popTestVectors {
+		privateKey, err := bls.NewPrivateKeyFromBytes(convertHexToPrivateKey(v.privateKey))
+		require.NoError(t, err)
+		require.Equal(t, convertHexToPublicKey(v.publicKey), bls.CompressPublicKey(privateKey.PublicKey()))
+		proof := privateKey.PopProve()
+		require.Equal(t, convertHexToSignature(v.proof), proof.Bytes())
+		require.True(t, proof.PopVerify(privateKey.PublicKey()))
+	}
+}
+
+func TestPopReference(t *testing.T) {
+	popDST := []byte("BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+	for _, v := range popTestVectors {
+		privateKey := convertHexToPrivateKey(v.privateKey)
+		require.Equal(t, convertHexToPublicKey(v.publicKey), referencePublicKey(privateKey))
+		require.Equal(t, convertHexToSignature(v.proof), referenceSign(privateKey, convertHexToPublicKey(v.publicKey), popDST))
+	}
+}
+
+func TestPopVerify(t *testing.T) {
+	for _, v := range popTestVectors {
+		valid, err := bls.PopVerify(convertHexToSignature(v.proof), convertHexToPublicKey(v.publicKey))
+		require.NoError(t, err)
+		require.True(t, valid)
+	}
+	// A proof does not transfer to another key.
+	valid, err := bls.PopVerify(convertHexToSignature(popTestVectors[0].proof), convertHexToPublicKey(popTestVectors[1].publicKey))
+	require.NoError(t, err)
+	require.False(t, valid)
+	// A proof is not a signature over the public key under the signing DST.
+	privateKey, err := bls.NewPrivateKeyFromBytes(convertHexToPrivateKey(popTestVectors[0].privateKey))
+	require.NoError(t, err)
+	signature := privateKey.Sign(convertHexToPublicKey(popTestVectors[0].publicKey))
+	require.False(t, signature.PopVerify(privateKey.PublicKey()))
+}
+
+func TestLoadPublicKeyIntoCacheWithPop(t *testing.T) {
+	require.ErrorIs(t, bls.LoadPublicKeyIntoCacheWithPop(convertHexToPublicKey(popTestVectors[0].publicKey), convertHexToSignature(popTestVectors[0].proof)), bls.ErrCacheNotEnabled)
+
+	bls.SetEnabledCaching(true)
+	defer bls.SetEnabledCaching(false)
+	defer bls.ClearCache()
+	require.NoError(t, bls.LoadPublicKeyIntoCacheWithPop(convertHexToPublicKey(popTestVectors[0].publicKey), convertHexToSignature(popTestVectors[0].proof)))
+	require.ErrorIs(t, bls.LoadPublicKeyIntoCacheWithPop(convertHexToPublicKey(popTestVectors[1].publicKey), convertHexToSignature(popTestVectors[0].proof)), bls.ErrInvalidPop)
+}
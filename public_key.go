@@ -1,7 +1,6 @@
 package bls
 
 import (
-	"errors"
 	"fmt"
 
 	blst "github.com/supranational/blst/bindings/go"
@@ -55,20 +54,18 @@ func newPublicKeyFromBytes(b []byte, loadInCache bool) (PublicKey, error) {
 
 func AggregatePublickKeys(pubs [][]byte) ([]byte, error) {
 	if len(pubs) == 0 {
-		return nil, errors.New("nil or empty public keys")
+		return nil, ErrNoPublicKeysToAggregate
 	}
 	agg := new(blst.P1Aggregate)
-	mulP1 := make([]*blst.P1Affine, 0, len(pubs))
-	for _, pubkey := range pubs {
-		pubKeyObj, err := NewPublicKeyFromBytes(pubkey)
-		if err != nil {
-			return nil, err
-		}
-		mulP1 = append(mulP1, pubKeyObj)
+	publicKeys, err := NewPublicKeysFromBytes(pubs)
+	if err != nil {
+		return nil, err
 	}
-	// No group check needed here since it is done in PublicKeyFromBytes
-	// Note the checks could be moved from PublicKeyFromBytes into Aggregate
-	// and take advantage of multi-threading.
+	mulP1 := make([]*blst.P1Affine, len(publicKeys))
+	for i, publicKey := range publicKeys {
+		mulP1[i] = publicKey
+	}
+	// No group check needed here since it is done at once by NewPublicKeysFromBytes.
 	agg.Aggregate(mulP1, false)
 	return agg.ToAffine().Compress(), nil
 }
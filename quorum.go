@@ -0,0 +1,204 @@
+package bls
+
+import (
+	"fmt"
+	"math"
+)
+
+// Quorum certificates, as used by HotStuff-like BFT protocols: a bitfield telling which validators of a set
+// signed, and the aggregate of their signatures over the same message. The certificate is valid when the
+// signers hold enough stake and the aggregate verifies against their keys.
+// Keys are aggregated with FastAggregateVerify, validators must have proven possession of their keys.
+
+// ValidatorSet is an ordered set of validators, the position of a validator is its bit in the certificates.
+type ValidatorSet struct {
+	publicKeys []PublicKey
+	stakes     []uint64
+	totalStake uint64
+}
+
+// QuorumCertificate is an aggregate signature together with the bitfield of its signers.
+type QuorumCertificate struct {
+	signers   []byte
+	signature *Signature
+}
+
+// NewValidatorSet creates a validator set, publicKeys[i] holds stakes[i].
+// Keys are validated once here, the decoded keys are kept for the verifications of certificates.
+func NewValidatorSet(publicKeys [][]byte, stakes []uint64) (*ValidatorSet, error) {
+	if len(publicKeys) == 0 {
+		return nil, ErrNoPublicKeysToAggregate
+	}
+	if len(publicKeys) != len(stakes) {
+		return nil, fmt.Errorf("bls(quorum): provided pubkeys and stakes have differing lengths. P: %d, S: %d", len(publicKeys), len(stakes))
+	}
+	decoded, err := NewPublicKeysFromBytes(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+	set := &ValidatorSet{
+		publicKeys: decoded,
+		stakes:     make([]uint64, len(stakes)),
+	}
+	for i := range publicKeys {
+		if set.totalStake > math.MaxUint64-stakes[i] {
+			return nil, fmt.Errorf("bls(quorum): total stake overflows uint64")
+		}
+		set.totalStake += stakes[i]
+		set.stakes[i] = stakes[i]
+	}
+	return set, nil
+}
+
+// Len returns the number of validators in the set.
+func (v *ValidatorSet) Len() int {
+	return len(v.publicKeys)
+}
+
+// TotalStake returns the stake of the whole set.
+func (v *ValidatorSet) TotalStake() uint64 {
+	return v.totalStake
+}
+
+// bitfieldLength is the length in bytes of the signers bitfield of the set.
+func (v *ValidatorSet) bitfieldLength() int {
+	return (len(v.publicKeys) + 7) / 8
+}
+
+// checkBitfield rejects bitfields of the wrong length or with bits set past the last validator.
+func (v *ValidatorSet) checkBitfield(signers []byte) error {
+	if len(signers) != v.bitfieldLength() {
+		return fmt.Errorf("bls(quorum): invalid signers bitfield length. should be %d", v.bitfieldLength())
+	}
+	if rem := len(v.publicKeys) % 8; rem != 0 && signers[len(signers)-1]>>rem != 0 {
+		return ErrSignerOutOfRange
+	}
+	return nil
+}
+
+func hasBit(bitfield []byte, i int) bool {
+	return bitfield[i/8]&(1<<(i%8)) != 0
+}
+
+// BuildQuorumCertificate aggregates sigs into a certificate, sigs[i] must be signed by validator signers[i].
+func BuildQuorumCertificate(set *ValidatorSet, signers []int, sigs [][]byte) (*QuorumCertificate, error) {
+	if len(signers) != len(sigs) {
+		return nil, fmt.Errorf("bls(quorum): provided signers and signatures have differing lengths. P: %d, S: %d", len(signers), len(sigs))
+	}
+	bitfield := make([]byte, set.bitfieldLength())
+	for _, signer := range signers {
+		if signer < 0 || signer >= set.Len() {
+			return nil, ErrSignerOutOfRange
+		}
+		if hasBit(bitfield, signer) {
+			return nil, ErrDuplicateSigner
+		}
+		bitfield[signer/8] |= 1 << (signer % 8)
+	}
+	aggregate, err := AggregateSignatures(sigs)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := NewSignatureFromBytes(aggregate)
+	if err != nil {
+		return nil, err
+	}
+	return &QuorumCertificate{signers: bitfield, signature: signature}, nil
+}
+
+// NewQuorumCertificateFromBytes decodes a certificate over set, the signature followed by the signers bitfield.
+func NewQuorumCertificateFromBytes(set *ValidatorSet, b []byte) (*QuorumCertificate, error) {
+	if len(b) != signatureLength+set.bitfieldLength() {
+		return nil, fmt.Errorf("bls(quorum): invalid certificate length. should be %d", signatureLength+set.bitfieldLength())
+	}
+	signature, err := NewSignatureFromBytes(b[:signatureLength])
+	if err != nil {
+		return nil, err
+	}
+	signers := b[signatureLength:]
+	if err := set.checkBitfield(signers); err != nil {
+		return nil, err
+	}
+	return &QuorumCertificate{signers: copyBytes(signers), signature: signature}, nil
+}
+
+// Bytes serializes the certificate, the signature followed by the signers bitfield.
+func (q *QuorumCertificate) Bytes() []byte {
+	return append(q.signature.Bytes(), q.signers...)
+}
+
+// Signers returns the indices of the validators which signed the certificate.
+func (q *QuorumCertificate) Signers() []int {
+	var signers []int
+	for i := 0; i < len(q.signers)*8; i++ {
+		if hasBit(q.signers, i) {
+			signers = append(signers, i)
+		}
+	}
+	return signers
+}
+
+// Signature returns the aggregate signature of the certificate.
+func (q *QuorumCertificate) Signature() *Signature {
+	return q.signature
+}
+
+// Merge combines two certificates over the same set and message. Their signers must be disjoint,
+// otherwise the signatures of the common signers would be counted twice in the aggregate.
+func (q *QuorumCertificate) Merge(other *QuorumCertificate) (*QuorumCertificate, error) {
+	if len(q.signers) != len(other.signers) {
+		return nil, fmt.Errorf("bls(quorum): certificates have differing bitfield lengths. %d, %d", len(q.signers), len(other.signers))
+	}
+	signers := make([]byte, len(q.signers))
+	for i := range signers {
+		if q.signers[i]&other.signers[i] != 0 {
+			return nil, ErrDuplicateSigner
+		}
+		signers[i] = q.signers[i] | other.signers[i]
+	}
+	aggregate, err := AggregateSignatures([][]byte{q.signature.Bytes(), other.signature.Bytes()})
+	if err != nil {
+		return nil, err
+	}
+	signature, err := NewSignatureFromBytes(aggregate)
+	if err != nil {
+		return nil, err
+	}
+	return &QuorumCertificate{signers: signers, signature: signature}, nil
+}
+
+// Stake returns the stake held by the signers of the certificate.
+func (q *QuorumCertificate) Stake(set *ValidatorSet) (uint64, error) {
+	if err := set.checkBitfield(q.signers); err != nil {
+		return 0, err
+	}
+	var stake uint64
+	for i := range set.publicKeys {
+		if hasBit(q.signers, i) {
+			// Cannot overflow, the total stake of the set fits.
+			stake += set.stakes[i]
+		}
+	}
+	return stake, nil
+}
+
+// Verify checks that the signers hold at least threshold stake, then verifies the aggregate signature of msg.
+func (q *QuorumCertificate) Verify(set *ValidatorSet, msg []byte, threshold uint64) (bool, error) {
+	stake, err := q.Stake(set)
+	if err != nil {
+		return false, err
+	}
+	if stake < threshold {
+		return false, ErrInsufficientStake
+	}
+	publicKeys := make([]PublicKey, 0, set.Len())
+	for i, publicKey := range set.publicKeys {
+		if hasBit(q.signers, i) {
+			publicKeys = append(publicKeys, publicKey)
+		}
+	}
+	if len(publicKeys) == 0 {
+		return false, nil
+	}
+	return q.signature.VerifyAggregate(msg, publicKeys), nil
+}
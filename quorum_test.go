@@ -0,0 +1,110 @@
+package bls_test
+
+import (
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestValidatorSet(t *testing.T, stakes []uint64) ([]*bls.PrivateKey, *bls.ValidatorSet) {
+	privateKeys := make([]*bls.PrivateKey, len(stakes))
+	publicKeys := make([][]byte, len(stakes))
+	for i := range stakes {
+		privateKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		privateKeys[i] = privateKey
+		publicKeys[i] = bls.CompressPublicKey(privateKey.PublicKey())
+	}
+	set, err := bls.NewValidatorSet(publicKeys, stakes)
+	require.NoError(t, err)
+	return privateKeys, set
+}
+
+func TestQuorumCertificate(t *testing.T) {
+	stakes := []uint64{10, 20, 30, 5, 5, 10, 10, 5, 5}
+	privateKeys, set := newTestValidatorSet(t, stakes)
+	require.Equal(t, uint64(100), set.TotalStake())
+	msg := []byte("block 42")
+	sign := func(signers ...int) [][]byte {
+		sigs := make([][]byte, len(signers))
+		for i, signer := range signers {
+			sigs[i] = privateKeys[signer].Sign(msg).Bytes()
+		}
+		return sigs
+	}
+
+	qc1, err := bls.BuildQuorumCertificate(set, []int{1, 2}, sign(1, 2))
+	require.NoError(t, err)
+	qc2, err := bls.BuildQuorumCertificate(set, []int{8, 0, 5}, sign(8, 0, 5))
+	require.NoError(t, err)
+
+	// 50 of stake is below a 2/3 threshold.
+	valid, err := qc1.Verify(set, msg, 67)
+	require.ErrorIs(t, err, bls.ErrInsufficientStake)
+	require.False(t, valid)
+
+	qc, err := qc1.Merge(qc2)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 5, 8}, qc.Signers())
+	stake, err := qc.Stake(set)
+	require.NoError(t, err)
+	require.Equal(t, uint64(75), stake)
+	valid, err = qc.Verify(set, msg, 67)
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, err = qc.Verify(set, []byte("block 43"), 67)
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	// Round trip through bytes.
+	decoded, err := bls.NewQuorumCertificateFromBytes(set, qc.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, qc.Bytes(), decoded.Bytes())
+	valid, err = decoded.Verify(set, msg, 67)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	// A certificate claiming an extra signer does not verify.
+	forged := qc.Bytes()
+	forged[len(forged)-2] |= 1 << 3
+	decoded, err = bls.NewQuorumCertificateFromBytes(set, forged)
+	require.NoError(t, err)
+	valid, err = decoded.Verify(set, msg, 67)
+	require.NoError(t, err)
+	require.False(t, valid)
+}
+
+func TestQuorumCertificateInvalidSigners(t *testing.T) {
+	privateKeys, set := newTestValidatorSet(t, []uint64{1, 1, 1})
+	msg := []byte("block 42")
+	sig := privateKeys[0].Sign(msg).Bytes()
+
+	_, err := bls.BuildQuorumCertificate(set, []int{0, 0}, [][]byte{sig, sig})
+	require.ErrorIs(t, err, bls.ErrDuplicateSigner)
+	_, err = bls.BuildQuorumCertificate(set, []int{3}, [][]byte{sig})
+	require.ErrorIs(t, err, bls.ErrSignerOutOfRange)
+	_, err = bls.BuildQuorumCertificate(set, []int{-1}, [][]byte{sig})
+	require.ErrorIs(t, err, bls.ErrSignerOutOfRange)
+	_, err = bls.BuildQuorumCertificate(set, []int{0, 1}, [][]byte{sig})
+	require.Error(t, err)
+
+	qc, err := bls.BuildQuorumCertificate(set, []int{0}, [][]byte{sig})
+	require.NoError(t, err)
+	_, err = qc.Merge(qc)
+	require.ErrorIs(t, err, bls.ErrDuplicateSigner)
+
+	// Bits past the last validator.
+	b := qc.Bytes()
+	b[len(b)-1] |= 1 << 3
+	_, err = bls.NewQuorumCertificateFromBytes(set, b)
+	require.ErrorIs(t, err, bls.ErrSignerOutOfRange)
+	// Bitfield of the wrong length.
+	_, err = bls.NewQuorumCertificateFromBytes(set, append(qc.Bytes(), 0))
+	require.Error(t, err)
+
+	_, err = bls.NewValidatorSet([][]byte{bls.CompressPublicKey(privateKeys[0].PublicKey())}, []uint64{1, 2})
+	require.Error(t, err)
+	_, err = bls.NewValidatorSet([][]byte{bls.InfiniteSignature[:48]}, []uint64{1})
+	require.Error(t, err)
+}
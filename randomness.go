@@ -0,0 +1,163 @@
+package bls
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// Domain separation of the hash the scalars are derived from in deterministic mode.
+var batchTranscriptDST = []byte("BLS_BATCH_VERIFY_FIAT_SHAMIR_")
+
+// Length of the scalars in deterministic mode. They are known as soon as the batch is, so an attacker can grind
+// inputs offline looking for scalars which cancel invalid signatures out, which takes about 2^deterministicRandBits
+// hashes. Random scalars are unknown to the attacker, randBitsEntropy bits are enough for them.
+const deterministicRandBits = 128
+
+// batchRandomness is the source of the scalars of batch verification.
+type batchRandomness struct {
+	// reader is nil in deterministic mode.
+	reader io.Reader
+	// mu serializes reads from a reader set by SetBatchRandomness, crypto/rand is safe for concurrent use.
+	mu *sync.Mutex
+}
+
+var batchRandomnessSource atomic.Pointer[batchRandomness]
+
+func init() {
+	batchRandomnessSource.Store(&batchRandomness{reader: rand.Reader})
+}
+
+// SetBatchRandomness sets the reader the scalars of batch verification are read from, crypto/rand if nil.
+// The reader is used under a lock and read once per batch. If it fails, verifications return an error.
+func SetBatchRandomness(reader io.Reader) {
+	if reader == nil {
+		batchRandomnessSource.Store(&batchRandomness{reader: rand.Reader})
+		return
+	}
+	batchRandomnessSource.Store(&batchRandomness{reader: reader, mu: new(sync.Mutex)})
+}
+
+// SetDeterministicBatchRandomness derives the scalars of batch verification from a hash of every signature,
+// public key and message of the batch (Fiat-Shamir), so that verifications are reproducible and do not need
+// entropy. The scalars are public once the batch is fixed, so an attacker can search offline for a batch whose
+// invalid signatures cancel out: scalars are 128 bits long instead of 64 to keep that search at about 2^128
+// hashes, which makes batches slower to verify. SetBatchRandomness switches back to a reader.
+func SetDeterministicBatchRandomness() {
+	batchRandomnessSource.Store(&batchRandomness{})
+}
+
+func deterministicBatchRandomness() bool {
+	return batchRandomnessSource.Load().reader == nil
+}
+
+// batchRandomBytes returns n bytes from the batch randomness source. transcript writes the inputs of the batch,
+// it is only called in deterministic mode.
+func batchRandomBytes(n int, transcript func(h hash.Hash)) ([]byte, error) {
+	randomBytes := make([]byte, n)
+	source := batchRandomnessSource.Load()
+	if source.reader == nil {
+		h := sha256.New()
+		h.Write(batchTranscriptDST)
+		transcript(h)
+		seed := h.Sum(nil)
+		var counter [8]byte
+		for offset, block := 0, uint64(0); offset < n; block++ {
+			binary.BigEndian.PutUint64(counter[:], block)
+			h.Reset()
+			h.Write(seed)
+			h.Write(counter[:])
+			offset += copy(randomBytes[offset:], h.Sum(nil))
+		}
+		return randomBytes, nil
+	}
+	if source.mu != nil {
+		source.mu.Lock()
+	}
+	_, err := io.ReadFull(source.reader, randomBytes)
+	if source.mu != nil {
+		source.mu.Unlock()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBatchRandomness, err)
+	}
+	return randomBytes, nil
+}
+
+// batchScalars returns n non zero scalars packed little endian and their length in bits, randBitsEntropy or
+// deterministicRandBits in deterministic mode.
+func batchScalars(n int, transcript func(h hash.Hash)) ([]byte, int, error) {
+	bits := randBitsEntropy
+	if deterministicBatchRandomness() {
+		bits = deterministicRandBits
+	}
+	scalarLength := bits / 8
+	scalars, err := batchRandomBytes(n*scalarLength, transcript)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := 0; i < n; i++ {
+		scalars[i*scalarLength] |= 0x01
+	}
+	return scalars, bits, nil
+}
+
+// writeTranscript writes length prefixed inputs of a batch entry.
+func writeTranscript(h hash.Hash, inputs ...[]byte) {
+	var length [8]byte
+	for _, input := range inputs {
+		binary.BigEndian.PutUint64(length[:], uint64(len(input)))
+		h.Write(length[:])
+		h.Write(input)
+	}
+}
+
+// bytesTranscript writes the inputs of a batch given as bytes, augs are optional message prefixes.
+func bytesTranscript(sigs [][]byte, msgs [][]byte, pubKeys [][]byte, dst []byte, augs [][]byte) func(h hash.Hash) {
+	return func(h hash.Hash) {
+		writeTranscript(h, dst)
+		for i := range sigs {
+			writeTranscript(h, sigs[i], pubKeys[i], msgs[i])
+			if augs != nil {
+				writeTranscript(h, augs[i])
+			}
+		}
+	}
+}
+
+// entriesTranscript writes the inputs of a batch of decoded entries.
+func entriesTranscript(entries []batchEntry) func(h hash.Hash) {
+	return func(h hash.Hash) {
+		for i := range entries {
+			writeTranscript(h, entries[i].dst, entries[i].signature.Compress(), entries[i].publicKey.Compress(), entries[i].msg)
+		}
+	}
+}
+
+// scalarsRandFunc hands the scalars of bits bits out to blst one after the other. blst asks for them from
+// several threads, so which entry gets which scalar depends on scheduling: it is only used with random scalars,
+// deterministic batches apply theirs by index.
+func scalarsRandFunc(scalars []byte, bits int) func(*blst.Scalar) {
+	var next atomic.Int64
+	return func(scalar *blst.Scalar) {
+		*scalar = *entryScalar(scalars, bits, int(next.Add(1)-1))
+	}
+}
+
+// entryScalar returns the scalar of index i of bits bits.
+func entryScalar(scalars []byte, bits int, i int) *blst.Scalar {
+	scalarLength := bits / 8
+	var rbytes [scalarBytes]byte
+	// blst reads the scalar big endian and uses its low bits bits.
+	for j := 0; j < scalarLength; j++ {
+		rbytes[scalarBytes-1-j] = scalars[i*scalarLength+j]
+	}
+	return new(blst.Scalar).FromBEndian(rbytes[:])
+}
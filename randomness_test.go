@@ -0,0 +1,164 @@
+package bls_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchRandomnessFailure(t *testing.T) {
+	bls.SetBatchRandomness(failingReader{})
+	defer bls.SetBatchRandomness(nil)
+	sigs, msgs, pubKeys := generateBatch(t, 10)
+
+	_, err := bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	require.ErrorIs(t, err, bls.ErrBatchRandomness)
+	_, err = bls.VerifyMultipleSignaturesBisect(sigs, msgs, pubKeys)
+	require.ErrorIs(t, err, bls.ErrBatchRandomness)
+	_, err = bls.VerifySignatureSets(generateBlockSets(t))
+	require.ErrorIs(t, err, bls.ErrBatchRandomness)
+
+	bls.SetVerifyWorkers(3)
+	_, err = bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	bls.SetVerifyWorkers(0)
+	require.ErrorIs(t, err, bls.ErrBatchRandomness)
+
+	v := bls.NewBatchVerifier(4, time.Millisecond)
+	defer v.Close()
+	errs := make(chan error, 1)
+	require.NoError(t, v.VerifyLaneCallback(0, sigs[0], msgs[0], pubKeys[0], func(valid bool, err error) {
+		require.False(t, valid)
+		errs <- err
+	}))
+	require.ErrorIs(t, <-errs, bls.ErrBatchRandomness)
+}
+
+func TestBatchRandomnessReader(t *testing.T) {
+	// Enough bytes for a single batch of 10 signatures.
+	bls.SetBatchRandomness(bytes.NewReader(bytes.Repeat([]byte{0x5a}, 80)))
+	defer bls.SetBatchRandomness(nil)
+	sigs, msgs, pubKeys := generateBatch(t, 10)
+
+	valid, err := bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+	_, err = bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	require.ErrorIs(t, err, bls.ErrBatchRandomness)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestDeterministicBatchRandomness(t *testing.T) {
+	bls.SetDeterministicBatchRandomness()
+	defer bls.SetBatchRandomness(nil)
+
+	for _, invalid := range [][]int{nil, {3}, {0, 9}} {
+		sigs, msgs, pubKeys := generateBatch(t, 10, invalid...)
+		valid, err := bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+		require.NoError(t, err)
+		require.Equal(t, invalid == nil, valid)
+		found, err := bls.VerifyMultipleSignaturesBisect(sigs, msgs, pubKeys)
+		require.NoError(t, err)
+		require.Equal(t, len(invalid), len(found))
+	}
+
+	// Two invalid signatures which cancel out in an unweighted sum are still caught.
+	sigs, msgs, pubKeys := generateBatch(t, 10)
+	first, err := bls.NewSignatureFromBytes(sigs[0])
+	require.NoError(t, err)
+	second, err := bls.NewSignatureFromBytes(sigs[1])
+	require.NoError(t, err)
+	offset, err := bls.NewSignatureFromBytes(sigs[2])
+	require.NoError(t, err)
+	sigs[0] = first.Add(offset).Bytes()
+	sigs[1] = second.Subtract(offset).Bytes()
+	valid, err := bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.False(t, valid)
+	found, err := bls.VerifyMultipleSignaturesBisect(sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1}, found)
+
+	valid, err = bls.VerifySignatureSets(generateBlockSets(t))
+	require.NoError(t, err)
+	require.True(t, valid)
+}
+
+// Deterministic scalars are longer, every batch verification must use them consistently.
+func TestDeterministicBatchRandomnessPaths(t *testing.T) {
+	bls.SetDeterministicBatchRandomness()
+	defer bls.SetBatchRandomness(nil)
+
+	sameSigs, msg, samePubKeys := generateSameMessageBatch(t, 20, 4)
+	found, err := bls.VerifySameMessageSignatures(sameSigs, msg, samePubKeys)
+	require.NoError(t, err)
+	require.Equal(t, []int{4}, found)
+
+	bls.SetVerifyWorkers(2)
+	sigs, msgs, pubKeys := generateBatch(t, 20, 13)
+	valid, err := bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	bls.SetVerifyWorkers(0)
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	for _, v := range minSigTestVectors {
+		sigs := make([][]byte, len(v.signatures))
+		msgs := make([][]byte, len(v.signatures))
+		publicKeys := make([][]byte, len(v.signatures))
+		for i, signature := range v.signatures {
+			sigs[i] = convertHexToMinSigSignature(signature)
+			msgs[i] = convertHexToMessage(ciphersuiteMessages[i])
+			publicKeys[i] = convertHexToMinSigPublicKey(minSigPublicKeys[i])
+		}
+		valid, err := v.ciphersuite.VerifyMultipleSignaturesMinSig(sigs, msgs, publicKeys)
+		require.NoError(t, err)
+		require.True(t, valid, v.name)
+		msgs[0] = msgs[1]
+		valid, err = v.ciphersuite.VerifyMultipleSignaturesMinSig(sigs, msgs, publicKeys)
+		require.NoError(t, err)
+		require.False(t, valid, v.name)
+	}
+}
+
+// Deterministic scalars weight every entry by its index, whatever the verify workers or blst's threads do.
+func TestDeterministicBatchScalarsByIndex(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 40)
+	products := func() ([]byte, []byte) {
+		lhs, sig, err := bls.BatchProducts(sigs, msgs, pubKeys)
+		require.NoError(t, err)
+		return lhs, sig
+	}
+	randomLhs, randomSig := products()
+	otherLhs, otherSig := products()
+	require.NotEqual(t, randomLhs, otherLhs)
+	require.NotEqual(t, randomSig, otherSig)
+
+	bls.SetDeterministicBatchRandomness()
+	defer bls.SetBatchRandomness(nil)
+	defer bls.SetVerifyWorkers(0)
+	lhs, sig := products()
+	for _, workers := range []int{0, 3, 5, 0} {
+		bls.SetVerifyWorkers(workers)
+		otherLhs, otherSig := products()
+		require.Equal(t, lhs, otherLhs, "workers=%d", workers)
+		require.Equal(t, sig, otherSig, "workers=%d", workers)
+	}
+
+	var minSigs, minSigPubKeys [][]byte
+	for i := range msgs {
+		privateKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		minSigs = append(minSigs, bls.PopCiphersuite.SignMinSig(privateKey, msgs[i]).Bytes())
+		minSigPubKeys = append(minSigPubKeys, bls.CompressMinSigPublicKey(privateKey.MinSigPublicKey()))
+	}
+	product, err := bls.PopCiphersuite.MinSigBatchProduct(minSigs, msgs, minSigPubKeys)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		other, err := bls.PopCiphersuite.MinSigBatchProduct(minSigs, msgs, minSigPubKeys)
+		require.NoError(t, err)
+		require.Equal(t, product, other)
+	}
+}
@@ -1,10 +1,7 @@
 package bls
 
 import (
-	"bytes"
-	"crypto/rand"
 	"fmt"
-	"sync"
 
 	"github.com/pkg/errors"
 	blst "github.com/supranational/blst/bindings/go"
@@ -59,11 +56,25 @@ func (s Signature) VerifyAggregate(msg []byte, publicKeys []PublicKey) bool {
 	return s.affine.FastAggregateVerify(true, affines, msg, eth2Curve)
 }
 
+// AggregateVerify verify signature against many public keys, each of which signed its own message.
+func (s Signature) AggregateVerify(msgs [][]byte, publicKeys []PublicKey, enforceDistinct bool) bool {
+	valid, _ := PopCiphersuite.AggregateVerifySignature(&s, msgs, publicKeys, enforceDistinct)
+	return valid
+}
+
 // Verify verify signature against one public key.
 func (s Signature) Bytes() []byte {
 	return s.affine.Compress()
 }
 
+// Signature returns s, so that a decoded signature is a SignatureSource.
+func (s *Signature) Signature() (*Signature, error) {
+	if s == nil || s.affine == nil {
+		return nil, ErrDeserializeSignature
+	}
+	return s, nil
+}
+
 // Verify verify signature against one public key.
 func (s Signature) Verify(msg []byte, pk PublicKey) bool {
 	return s.affine.Verify(false, pk, false, msg, eth2Curve)
@@ -71,39 +82,17 @@ func (s Signature) Verify(msg []byte, pk PublicKey) bool {
 
 // VerifyAggregate verify signature against many public keys.
 func VerifyAggregate(signature []byte, msg []byte, publicKeysBytes [][]byte) (bool, error) {
-	if len(publicKeysBytes) == 0 && bytes.Equal(InfiniteSignature[:], signature) {
-		return true, nil
-	}
-	sig, err := NewSignatureFromBytes(signature)
-	if err != nil {
-		return false, err
-	}
-
-	publicKeys := []PublicKey{}
-	for _, publicKey := range publicKeysBytes {
-		key, err := NewPublicKeyFromBytes(publicKey)
-		if err != nil {
-			return false, err
-		}
-		publicKeys = append(publicKeys, key)
-	}
+	return PopCiphersuite.VerifyAggregate(signature, msg, publicKeysBytes)
+}
 
-	return sig.VerifyAggregate(msg, publicKeys), nil
+// AggregateVerify verify signature against many public keys, each of which signed its own message.
+func AggregateVerify(signature []byte, msgs [][]byte, publicKeysBytes [][]byte, enforceDistinct bool) (bool, error) {
+	return PopCiphersuite.AggregateVerify(signature, msgs, publicKeysBytes, enforceDistinct)
 }
 
 // Verify verify signature against one public key.
 func Verify(signature []byte, msg []byte, publicKeyBytes []byte) (bool, error) {
-	sig, err := NewSignatureFromBytes(signature)
-	if err != nil {
-		return false, err
-	}
-
-	publicKey, err := NewPublicKeyFromBytes(publicKeyBytes)
-	if err != nil {
-		return false, err
-	}
-
-	return sig.Verify(msg, publicKey), nil
+	return PopCiphersuite.Verify(signature, msg, publicKeyBytes)
 }
 
 // VerifyMultipleSignatures verifies a non-singular set of signatures and its respective pubkeys and messages.
@@ -114,44 +103,66 @@ func Verify(signature []byte, msg []byte, publicKeyBytes []byte) (bool, error) {
 // e(S*, G) = \prod_{i=1}^n \prod_{j=1}^{m_i} e(P'_{i,j}, M_{i,j})
 // Using this we can verify multiple signatures safely.
 func VerifyMultipleSignatures(sigs [][]byte, msgs [][]byte, pubKeys [][]byte) (bool, error) {
+	return PopCiphersuite.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+}
+
+// verifyMultipleSignatures is VerifyMultipleSignatures over any DST, augs are optional message prefixes.
+// Tuples which verified before are skipped.
+func verifyMultipleSignatures(sigs [][]byte, msgs [][]byte, pubKeys [][]byte, dst []byte, augs [][]byte) (bool, error) {
 	if len(sigs) == 0 || len(pubKeys) == 0 {
 		return false, nil
 	}
-	rawSigs := new(blst.P2Affine).BatchUncompress(sigs)
-
 	length := len(sigs)
 	if length != len(pubKeys) || length != len(msgs) {
 		return false, errors.Errorf("provided signatures, pubkeys and messages have differing lengths. S: %d, P: %d,M %d",
 			length, len(pubKeys), len(msgs))
 	}
+	return verifications.verifyBatch(dst, sigs, msgs, pubKeys, augs, func(sigs, msgs, pubKeys, augs [][]byte) (bool, error) {
+		return verifyMultipleSignaturesUncached(sigs, msgs, pubKeys, dst, augs)
+	})
+}
+
+func verifyMultipleSignaturesUncached(sigs [][]byte, msgs [][]byte, pubKeys [][]byte, dst []byte, augs [][]byte) (bool, error) {
+	rawSigs := batchUncompress(sigs)
+	length := len(sigs)
+	publicKeys, err := decodePublicKeys(pubKeys, false)
+	if err != nil {
+		return false, err
+	}
 	mulP1Aff := make([]*blst.P1Affine, length)
 	rawMsgs := make([]blst.Message, length)
-
 	for i := 0; i < length; i++ {
-		pk, err := newPublicKeyFromBytes(pubKeys[i], false)
-		if err != nil {
-			return false, err
-		}
-		mulP1Aff[i] = pk
+		mulP1Aff[i] = publicKeys[i]
 		rawMsgs[i] = msgs[i][:]
 	}
-	// Secure source of RNG
-	randLock := new(sync.Mutex)
-
-	randFunc := func(scalar *blst.Scalar) {
-		var rbytes [scalarBytes]byte
-		randLock.Lock()
-		rand.Read(rbytes[:])
-		randLock.Unlock()
-		// Protect against the generator returning 0. Since the scalar value is
-		// derived from a big endian byte slice, we take the last byte.
-		rbytes[len(rbytes)-1] |= 0x01
-		scalar.FromBEndian(rbytes[:])
+	// Signatures which cannot be decoded or are not in the group make the batch invalid.
+	if rawSigs == nil {
+		return false, nil
+	}
+	invalid, err := invalidG2(rawSigs, sigs)
+	if err != nil {
+		return false, err
+	}
+	if len(invalid) > 0 {
+		return false, nil
+	}
+	// Repeated messages are grouped into a single Miller loop by verifyBatch, which also honours the verify workers
+	// and applies deterministic scalars by index, whereas blst hands them out in the order its threads ask.
+	if verifyWorkers.Load() != nil || deterministicBatchRandomness() || (augs == nil && !distinctMessages(msgs)) {
+		return verifyMultipleSignaturesBatch(rawSigs, mulP1Aff, msgs, dst, augs)
 	}
+	scalars, bits, err := batchScalars(length, bytesTranscript(sigs, msgs, pubKeys, dst, augs))
+	if err != nil {
+		return false, err
+	}
+	randFunc := scalarsRandFunc(scalars, bits)
 	dummySig := new(blst.P2Affine)
 
-	// Validate signatures since we uncompress them here. Public keys should already be validated.
-	return dummySig.MultipleAggregateVerify(rawSigs, true, mulP1Aff, false, rawMsgs, eth2Curve, randFunc, randBitsEntropy), nil
+	// Signatures and public keys are already validated.
+	if augs != nil {
+		return dummySig.MultipleAggregateVerify(rawSigs, false, mulP1Aff, false, rawMsgs, dst, randFunc, bits, augs), nil
+	}
+	return dummySig.MultipleAggregateVerify(rawSigs, false, mulP1Aff, false, rawMsgs, dst, randFunc, bits), nil
 }
 
 func AggregateSignatures(sigs [][]byte) ([]byte, error) {
@@ -160,17 +171,11 @@ func AggregateSignatures(sigs [][]byte) ([]byte, error) {
 	}
 
 	agg := new(blst.P2Aggregate)
-	mulP2 := make([]*blst.P2Affine, 0, len(sigs))
-	for _, pubkey := range sigs {
-		sigObj, err := NewSignatureFromBytes(pubkey)
-		if err != nil {
-			return nil, err
-		}
-		mulP2 = append(mulP2, sigObj.affine)
-	}
-	// No group check needed here since it is done in PublicKeyFromBytes
-	// Note the checks could be moved from PublicKeyFromBytes into Aggregate
-	// and take advantage of multi-threading.
+	mulP2, err := decodeSignatures(sigs)
+	if err != nil {
+		return nil, err
+	}
+	// No group check needed here since it is done at once by decodeSignatures.
 	agg.Aggregate(mulP2, false)
 	return agg.ToAffine().Compress(), nil
 }
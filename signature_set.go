@@ -0,0 +1,96 @@
+package bls
+
+import (
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// SignatureSet is a signature of Message by the aggregate of PublicKeys, a single key for an ordinary
+// signature and many for an aggregate attestation. Public keys are expected to be validated already,
+// as done by NewPublicKeyFromBytes.
+type SignatureSet struct {
+	// Signature is a *Signature, or a *LazySignature decoded by the verification.
+	Signature  SignatureSource
+	Message    []byte
+	PublicKeys []PublicKey
+}
+
+// NewSignatureSet decodes a signature set.
+func NewSignatureSet(signature []byte, msg []byte, publicKeys [][]byte) (*SignatureSet, error) {
+	sig, err := NewSignatureFromBytes(signature)
+	if err != nil {
+		return nil, err
+	}
+	return newSignatureSet(sig, msg, publicKeys)
+}
+
+// NewLazySignatureSet is NewSignatureSet for a lazy signature, decoded when the set is verified.
+func NewLazySignatureSet(signature *LazySignature, msg []byte, publicKeys [][]byte) (*SignatureSet, error) {
+	return newSignatureSet(signature, msg, publicKeys)
+}
+
+func newSignatureSet(signature SignatureSource, msg []byte, publicKeys [][]byte) (*SignatureSet, error) {
+	set := &SignatureSet{Signature: signature, Message: msg, PublicKeys: make([]PublicKey, len(publicKeys))}
+	for i, publicKey := range publicKeys {
+		var err error
+		if set.PublicKeys[i], err = NewPublicKeyFromBytes(publicKey); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// batchEntry aggregates the public keys of the set, false if the signature cannot be decoded, if the set has no
+// key or if the aggregate is the point at infinity, which FastAggregateVerify rejects as well.
+func (s *SignatureSet) batchEntry() (batchEntry, bool) {
+	if s == nil || missingSignature(s.Signature) || len(s.PublicKeys) == 0 {
+		return batchEntry{}, false
+	}
+	signature, err := s.Signature.Signature()
+	if err != nil {
+		return batchEntry{}, false
+	}
+	publicKey := (*blst.P1Affine)(s.PublicKeys[0])
+	if len(s.PublicKeys) > 1 {
+		affines := make([]*blst.P1Affine, len(s.PublicKeys))
+		for i, pk := range s.PublicKeys {
+			affines[i] = pk
+		}
+		agg := new(blst.P1Aggregate)
+		agg.Aggregate(affines, false)
+		publicKey = agg.ToAffine()
+	}
+	if publicKey.Equals(new(blst.P1Affine)) {
+		return batchEntry{}, false
+	}
+	return batchEntry{
+		signature: signature.affine,
+		publicKey: publicKey,
+		msg:       s.Message,
+		dst:       eth2Curve,
+	}, true
+}
+
+// VerifySignatureSets verifies every set in a single multi-pairing, the keys of each set are aggregated first.
+// It is false if any set is invalid, has no public key or keys summing to infinity, or if there is no set at all.
+// Lazy signatures are decoded at once.
+func VerifySignatureSets(sets []*SignatureSet) (bool, error) {
+	if len(sets) == 0 {
+		return false, nil
+	}
+	sources := make([]SignatureSource, 0, len(sets))
+	for _, set := range sets {
+		if set != nil {
+			sources = append(sources, set.Signature)
+		}
+	}
+	decodeLazySources(sources)
+	entries := make([]batchEntry, len(sets))
+	for i, set := range sets {
+		entry, ok := set.batchEntry()
+		if !ok {
+			return false, nil
+		}
+		entries[i] = entry
+	}
+	return verifyBatch(entries)
+}
@@ -0,0 +1,101 @@
+package bls_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+// generateAggregateSet returns a set of n signers of msg.
+func generateAggregateSet(t *testing.T, n int, msg []byte) *bls.SignatureSet {
+	var sigs, pubKeys [][]byte
+	for i := 0; i < n; i++ {
+		privateKey, err := bls.GenerateKey()
+		require.NoError(t, err)
+		sigs = append(sigs, privateKey.Sign(msg).Bytes())
+		pubKeys = append(pubKeys, bls.CompressPublicKey(privateKey.PublicKey()))
+	}
+	signature, err := bls.AggregateSignatures(sigs)
+	require.NoError(t, err)
+	set, err := bls.NewSignatureSet(signature, msg, pubKeys)
+	require.NoError(t, err)
+	return set
+}
+
+// generateBlockSets returns the sets of a block: single signatures followed by aggregate attestations.
+func generateBlockSets(t *testing.T) []*bls.SignatureSet {
+	sigs, msgs, pubKeys := generateBatch(t, 3)
+	var sets []*bls.SignatureSet
+	for i := range sigs {
+		set, err := bls.NewSignatureSet(sigs[i], msgs[i], [][]byte{pubKeys[i]})
+		require.NoError(t, err)
+		sets = append(sets, set)
+	}
+	for i := 0; i < 2; i++ {
+		sets = append(sets, generateAggregateSet(t, 4, []byte(fmt.Sprintf("attestation data %d", i))))
+	}
+	return sets
+}
+
+func verifySignatureSets(t *testing.T, sets []*bls.SignatureSet) bool {
+	valid, err := bls.VerifySignatureSets(sets)
+	require.NoError(t, err)
+	return valid
+}
+
+func TestVerifySignatureSets(t *testing.T) {
+	sets := generateBlockSets(t)
+	require.True(t, verifySignatureSets(t, sets))
+	require.True(t, verifySignatureSets(t, sets[3:]))
+
+	// A signer missing from an aggregate set.
+	tampered := *sets[4]
+	tampered.PublicKeys = tampered.PublicKeys[1:]
+	require.False(t, verifySignatureSets(t, append(sets[:4:4], &tampered)))
+	// A wrong message.
+	tampered = *sets[0]
+	tampered.Message = []byte("tampered")
+	require.False(t, verifySignatureSets(t, append([]*bls.SignatureSet{&tampered}, sets[1:]...)))
+
+	tampered = *sets[0]
+	tampered.PublicKeys = nil
+	require.False(t, verifySignatureSets(t, []*bls.SignatureSet{&tampered}))
+	// Keys summing to infinity would accept the infinite signature over any message.
+	publicKey := sets[0].PublicKeys[0]
+	cancelling, err := bls.NewSignatureSet(bls.InfiniteSignature[:], []byte("any message"),
+		[][]byte{bls.CompressPublicKey(publicKey), bls.CompressPublicKey(bls.NegatePublicKey(publicKey))})
+	require.NoError(t, err)
+	require.False(t, verifySignatureSets(t, []*bls.SignatureSet{cancelling}))
+	require.False(t, verifySignatureSets(t, append(sets, cancelling)))
+	require.False(t, verifySignatureSets(t, nil))
+
+	_, err = bls.NewSignatureSet(sets[0].Signature.Bytes(), sets[0].Message, [][]byte{make([]byte, 48)})
+	require.Error(t, err)
+	_, err = bls.NewSignatureSet(make([]byte, 96), sets[0].Message, nil)
+	require.Error(t, err)
+}
+
+func TestBatchVerifierSignatureSets(t *testing.T) {
+	sets := generateBlockSets(t)
+	tampered := *sets[3]
+	tampered.Message = []byte("tampered")
+	sets[3] = &tampered
+
+	v := bls.NewBatchVerifier(len(sets), time.Hour)
+	defer v.Close()
+	results := make([]chan bool, len(sets))
+	for i, set := range sets {
+		results[i] = make(chan bool, 1)
+		result := results[i]
+		require.NoError(t, v.VerifySetLaneCallback(0, set, func(valid bool, err error) {
+			require.NoError(t, err)
+			result <- valid
+		}))
+	}
+	for i := range sets {
+		require.Equal(t, i != 3, <-results[i], "set %d", i)
+	}
+}
@@ -0,0 +1,67 @@
+package bls
+
+import (
+	"bytes"
+	"io"
+
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// messageReader prefixes the message with the augmentation of the ciphersuite, if any.
+func (c *Ciphersuite) messageReader(r io.Reader, aug []byte) io.Reader {
+	if len(aug) == 0 {
+		return r
+	}
+	return io.MultiReader(bytes.NewReader(aug), r)
+}
+
+// SignReader a message read from r with BLS, without holding it in memory.
+func (c *Ciphersuite) SignReader(p *PrivateKey, r io.Reader) (*Signature, error) {
+	point, err := hashToG2Reader(c.messageReader(r, c.augmentation(p.PublicKey())), c.dst)
+	if err != nil {
+		return nil, err
+	}
+	return &Signature{affine: point.MultAssign(p.key).ToAffine()}, nil
+}
+
+// VerifySignatureReader verify signature of a message read from r against one public key.
+func (c *Ciphersuite) VerifySignatureReader(s *Signature, r io.Reader, pk PublicKey) (bool, error) {
+	point, err := hashToG2Reader(c.messageReader(r, c.augmentation(pk)), c.dst)
+	if err != nil {
+		return false, err
+	}
+	// e(pk, H(m)) == e(g1, sig)
+	lhs := blst.Fp12MillerLoop(point.ToAffine(), pk)
+	rhs := blst.Fp12MillerLoop(s.affine, blst.P1Generator().ToAffine())
+	return blst.Fp12FinalVerify(lhs, rhs), nil
+}
+
+// VerifyReader verify signature of a message read from r against one public key.
+func (c *Ciphersuite) VerifyReader(signature []byte, r io.Reader, publicKeyBytes []byte) (bool, error) {
+	sig, err := NewSignatureFromBytes(signature)
+	if err != nil {
+		return false, err
+	}
+
+	publicKey, err := NewPublicKeyFromBytes(publicKeyBytes)
+	if err != nil {
+		return false, err
+	}
+
+	return c.VerifySignatureReader(sig, r, publicKey)
+}
+
+// SignReader a message read from r with BLS, the result is the same as Sign over the whole message.
+func (p *PrivateKey) SignReader(r io.Reader) (*Signature, error) {
+	return PopCiphersuite.SignReader(p, r)
+}
+
+// VerifyReader verify signature of a message read from r against one public key.
+func (s Signature) VerifyReader(r io.Reader, pk PublicKey) (bool, error) {
+	return PopCiphersuite.VerifySignatureReader(&s, r, pk)
+}
+
+// VerifyReader verify signature of a message read from r against one public key.
+func VerifyReader(signature []byte, r io.Reader, publicKeyBytes []byte) (bool, error) {
+	return PopCiphersuite.VerifyReader(signature, r, publicKeyBytes)
+}
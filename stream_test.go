@@ -0,0 +1,84 @@
+package bls_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignReader(t *testing.T) {
+	privateKey, err := bls.NewPrivateKeyFromBytes(convertHexToPrivateKey("328388aff0d4a5b7dc9205abd374e7e98f3cd9f3418edb4eafda5fb16473d216"))
+	require.NoError(t, err)
+	for _, length := range []int{0, 1, 32, 63, 64, 65, 1 << 16} {
+		msg := make([]byte, length)
+		_, err := rand.Read(msg)
+		require.NoError(t, err)
+
+		signature, err := privateKey.SignReader(bytes.NewReader(msg))
+		require.NoError(t, err)
+		require.Equal(t, privateKey.Sign(msg).Bytes(), signature.Bytes())
+
+		valid, err := signature.VerifyReader(bytes.NewReader(msg), privateKey.PublicKey())
+		require.NoError(t, err)
+		require.True(t, valid)
+	}
+}
+
+func TestSignReaderCiphersuites(t *testing.T) {
+	msg := convertHexToMessage(ciphersuiteMessages[1])
+	for _, v := range ciphersuiteTestVectors {
+		t.Run(v.name, func(t *testing.T) {
+			privateKey, err := bls.NewPrivateKeyFromBytes(convertHexToPrivateKey(ciphersuitePrivateKeys[1]))
+			require.NoError(t, err)
+			signature, err := v.ciphersuite.SignReader(privateKey, bytes.NewReader(msg))
+			require.NoError(t, err)
+			require.Equal(t, convertHexToSignature(v.signatures[1]), signature.Bytes())
+
+			valid, err := v.ciphersuite.VerifyReader(convertHexToSignature(v.signatures[1]), bytes.NewReader(msg), convertHexToPublicKey(ciphersuitePublicKeys[1]))
+			require.NoError(t, err)
+			require.True(t, valid)
+		})
+	}
+}
+
+func TestVerifyReader(t *testing.T) {
+	msg := convertHexToMessage("5656565656565656565656565656565656565656565656565656565656565656")
+	signature := convertHexToSignature("af1390c3c47acdb37131a51216da683c509fce0e954328a59f93aebda7e4ff974ba208d9a4a2a2389f892a9d418d618418dd7f7a6bc7aa0da999a9d3a5b815bc085e14fd001f6a1948768a3f4afefc8b8240dda329f984cb345c6363272ba4fe")
+	publicKey := convertHexToPublicKey("b301803f8b5ac4a1133581fc676dfedc60d891dd5fa99028805e5ea5b08d3491af75d0707adab3b70c6a6a580217bf81")
+
+	valid, err := bls.VerifyReader(signature, bytes.NewReader(msg), publicKey)
+	require.NoError(t, err)
+	require.True(t, valid)
+
+	valid, err = bls.VerifyReader(signature, bytes.NewReader(msg[1:]), publicKey)
+	require.NoError(t, err)
+	require.False(t, valid)
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("disk on fire")
+}
+
+func TestSignReaderError(t *testing.T) {
+	privateKey, err := bls.GenerateKey()
+	require.NoError(t, err)
+	_, err = privateKey.SignReader(io.MultiReader(bytes.NewReader([]byte{1, 2, 3}), failingReader{}))
+	require.Error(t, err)
+}
+
+func BenchmarkSignReader(b *testing.B) {
+	privateKey, err := bls.GenerateKey()
+	require.NoError(b, err)
+	msg := make([]byte, 1<<20)
+	b.SetBytes(int64(len(msg)))
+	for i := 0; i < b.N; i++ {
+		privateKey.SignReader(bytes.NewReader(msg))
+	}
+}
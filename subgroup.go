@@ -0,0 +1,239 @@
+package bls
+
+import (
+	"context"
+	"fmt"
+	"hash"
+
+	"github.com/pkg/errors"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// Batch subgroup checks. If every point is in the subgroup so is Q = r_1 * P_1 + ... + r_n * P_n, while a point
+// with a component outside of the subgroup takes Q out of it unless the scalars cancel that component. The
+// cofactors have small prime factors, 3 for G1 and 13 for G2, so one combination is not enough: the check is
+// repeated with fresh scalars of subgroupScalarBits bits until the probability of missing an invalid point is
+// below 2^-64, as for batch verification. Small scalars keep each round a cheap multi-scalar multiplication.
+// In deterministic mode the scalars are known in advance and could be ground offline, points are checked one
+// by one instead.
+const (
+	subgroupScalarBits = 3
+	// A component of order 3 is cancelled by 3 of the 8 scalars: (3/8)^46 < 2^-64.
+	g1SubgroupRounds = 46
+	// Components have an order of at least 13, cancelled by at most 1 of the 8 scalars: (1/8)^22 < 2^-64.
+	g2SubgroupRounds = 22
+	// Below these numbers of points, checking them one by one is faster.
+	minG1BatchSubgroupCheck = 128
+	minG2BatchSubgroupCheck = 64
+)
+
+// Domain separation of the transcript of batch subgroup checks in deterministic mode.
+var subgroupTranscriptDST = []byte("SUBGROUP_CHECK_")
+
+func subgroupTranscript(encoded [][]byte) func(h hash.Hash) {
+	return func(h hash.Hash) {
+		writeTranscript(h, subgroupTranscriptDST)
+		writeTranscript(h, encoded...)
+	}
+}
+
+// invalidG1 returns the indices of the points outside of G1, none if they are all in G1. A random linear
+// combination of the points is checked first, one by one checks only identify the offenders of a failed batch.
+// encoded are the compressed points.
+func invalidG1(points []*blst.P1Affine, encoded [][]byte) ([]int, error) {
+	if len(points) >= minG1BatchSubgroupCheck && !deterministicBatchRandomness() {
+		scalars, err := batchRandomBytes(len(points)*g1SubgroupRounds, subgroupTranscript(encoded))
+		if err != nil {
+			return nil, err
+		}
+		valid, serial := true, verifyWorkers.Load() != nil
+		for round := 0; valid && round < g1SubgroupRounds; round++ {
+			roundScalars := scalars[round*len(points) : (round+1)*len(points)]
+			valid = p1sMult(points, roundScalars, subgroupScalarBits, serial).ToAffine().InG1()
+		}
+		if valid {
+			return nil, nil
+		}
+	}
+	var invalid []int
+	for i, point := range points {
+		if !point.InG1() {
+			invalid = append(invalid, i)
+		}
+	}
+	return invalid, nil
+}
+
+// invalidG2 is invalidG1 for G2.
+func invalidG2(points []*blst.P2Affine, encoded [][]byte) ([]int, error) {
+	if len(points) >= minG2BatchSubgroupCheck && !deterministicBatchRandomness() {
+		scalars, err := batchRandomBytes(len(points)*g2SubgroupRounds, subgroupTranscript(encoded))
+		if err != nil {
+			return nil, err
+		}
+		valid, serial := true, verifyWorkers.Load() != nil
+		for round := 0; valid && round < g2SubgroupRounds; round++ {
+			roundScalars := scalars[round*len(points) : (round+1)*len(points)]
+			valid = p2sMult(points, roundScalars, subgroupScalarBits, serial).ToAffine().InG2()
+		}
+		if valid {
+			return nil, nil
+		}
+	}
+	var invalid []int
+	for i, point := range points {
+		if !point.InG2() {
+			invalid = append(invalid, i)
+		}
+	}
+	return invalid, nil
+}
+
+// batchUncompress is P2Affine.BatchUncompress, decoding on the calling goroutine when the verify workers are
+// capped by SetVerifyWorkers.
+func batchUncompress(sigs [][]byte) []*blst.P2Affine {
+	if verifyWorkers.Load() == nil {
+		return new(blst.P2Affine).BatchUncompress(sigs)
+	}
+	points := make([]*blst.P2Affine, len(sigs))
+	for i, sig := range sigs {
+		if points[i] = new(blst.P2Affine).Uncompress(sig); points[i] == nil {
+			return nil
+		}
+	}
+	return points
+}
+
+// decodePublicKeys is NewPublicKeyFromBytes for many keys, the keys which are not cached are subgroup checked
+// at once. Errors tell the index of the first invalid key.
+func decodePublicKeys(pubs [][]byte, loadInCache bool) ([]PublicKey, error) {
+	return decodePublicKeysContext(context.Background(), pubs, loadInCache)
+}
+
+// decodePublicKeysContext is decodePublicKeys, abandoned once ctx is done.
+func decodePublicKeysContext(ctx context.Context, pubs [][]byte, loadInCache bool) ([]PublicKey, error) {
+	publicKeys := make([]PublicKey, len(pubs))
+	var (
+		unchecked []*blst.P1Affine
+		encoded   [][]byte
+		indices   []int
+	)
+	for i, pub := range pubs {
+		if i%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if len(pub) != publicKeyLength {
+			return nil, errors.Wrapf(fmt.Errorf("bls(public): invalid key length. should be %d", publicKeyLength), "public key %d", i)
+		}
+		if cachedAffine := pkCache.getAffineFromCache(pub); cachedAffine != nil {
+			publicKeys[i] = cachedAffine
+			continue
+		}
+		// Subgroup check NOT done when decompressing pubkey.
+		p := new(blst.P1Affine).Uncompress(pub)
+		if p == nil {
+			return nil, errors.Wrapf(ErrDeserializePublicKey, "public key %d", i)
+		}
+		// Uncompress only decodes the canonical encoding of infinity.
+		if pub[0]&infinityFlag != 0 {
+			return nil, errors.Wrapf(ErrInfinitePublicKey, "public key %d", i)
+		}
+		publicKeys[i] = p
+		unchecked = append(unchecked, p)
+		encoded = append(encoded, pub)
+		indices = append(indices, i)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	invalid, err := invalidG1(unchecked, encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(invalid) > 0 {
+		return nil, errors.Wrapf(ErrNotGroupPublicKey, "public key %d", indices[invalid[0]])
+	}
+	if loadInCache {
+		for j, i := range indices {
+			pkCache.loadAffineIntoCache(pubs[i], unchecked[j])
+		}
+	}
+	return publicKeys, nil
+}
+
+// decodeSignatures is NewSignatureFromBytes for many signatures, which are subgroup checked at once. Errors tell
+// the index of the first invalid signature.
+func decodeSignatures(sigs [][]byte) ([]*blst.P2Affine, error) {
+	return decodeSignaturesContext(context.Background(), sigs)
+}
+
+// decodeSignaturesContext is decodeSignatures, abandoned once ctx is done.
+func decodeSignaturesContext(ctx context.Context, sigs [][]byte) ([]*blst.P2Affine, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(sigs) == 0 {
+		return []*blst.P2Affine{}, nil
+	}
+	signatures := batchUncompress(sigs)
+	if signatures == nil {
+		// Find out which signature could not be decoded.
+		for i, sig := range sigs {
+			if len(sig) != signatureLength {
+				return nil, errors.Wrapf(fmt.Errorf("bls(signature): invalid signature length. should be %d", signatureLength), "signature %d", i)
+			}
+			if new(blst.P2Affine).Uncompress(sig) == nil {
+				return nil, errors.Wrapf(ErrDeserializeSignature, "signature %d", i)
+			}
+		}
+		return nil, ErrDeserializeSignature
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	invalid, err := invalidG2(signatures, sigs)
+	if err != nil {
+		return nil, err
+	}
+	if len(invalid) > 0 {
+		return nil, errors.Wrapf(ErrNotGroupSignature, "signature %d", invalid[0])
+	}
+	return signatures, nil
+}
+
+// NewPublicKeysFromBytes decodes many public keys like NewPublicKeyFromBytes, subgroup checking them at once.
+func NewPublicKeysFromBytes(pubs [][]byte) ([]PublicKey, error) {
+	return decodePublicKeys(pubs, true)
+}
+
+// NewSignaturesFromBytes decodes many signatures like NewSignatureFromBytes, subgroup checking them at once.
+func NewSignaturesFromBytes(sigs [][]byte) ([]*Signature, error) {
+	affines, err := decodeSignatures(sigs)
+	if err != nil {
+		return nil, err
+	}
+	signatures := make([]*Signature, len(affines))
+	for i, affine := range affines {
+		signatures[i] = &Signature{affine: affine}
+	}
+	return signatures, nil
+}
+
+// LoadPublicKeysIntoCache is LoadPublicKeyIntoCache for many keys, validated at once.
+func LoadPublicKeysIntoCache(publicKeys [][]byte, validate bool) error {
+	if !enabledCache {
+		return ErrCacheNotEnabled
+	}
+	if !validate {
+		for _, publicKey := range publicKeys {
+			if err := loadPublicKeyIntoCache(publicKey, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	_, err := decodePublicKeys(publicKeys, true)
+	return err
+}
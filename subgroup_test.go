@@ -0,0 +1,205 @@
+package bls_test
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// mult returns [k]p with scalar multiplications of at most 129 bits, blst expects larger scalars to multiply
+// points of the subgroup.
+func mult(p *blst.P1, k *big.Int) *blst.P1 {
+	littleEndian := func(n *big.Int, length int) []byte {
+		b := n.FillBytes(make([]byte, length))
+		slices.Reverse(b)
+		return b
+	}
+	shift := littleEndian(new(big.Int).Lsh(big.NewInt(1), 128), 17)
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	result := new(blst.P1)
+	power := *p
+	for k = new(big.Int).Set(k); k.Sign() > 0; k.Rsh(k, 128) {
+		limb := new(big.Int).And(k, mask)
+		if limb.Sign() > 0 {
+			result.AddAssign(power.Mult(littleEndian(limb, 16), 128))
+		}
+		power.MultAssign(shift, 129)
+	}
+	return result
+}
+
+// torsionPoint returns a point of order 3 of the curve, blst refuses to decode the obvious (0, 2).
+func torsionPoint(t *testing.T) *blst.P1Affine {
+	x := new(big.Int).Neg(new(big.Int).SetUint64(0xd201000000010000))
+	order, _ := new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+	// The order of the curve is 3 * h * r, where (x - 1)^2 = 9 * h.
+	h := new(big.Int).Sub(x, big.NewInt(1))
+	h.Mul(h, h).Div(h, big.NewInt(9))
+	for i := byte(1); ; i++ {
+		b := make([]byte, 48)
+		b[0] = 0x80
+		b[47] = i
+		q := new(blst.P1Affine).Uncompress(b)
+		if q == nil {
+			continue
+		}
+		point := new(blst.P1)
+		point.FromAffine(q)
+		torsion := mult(point, new(big.Int).Mul(h, order)).ToAffine()
+		if torsion.Compress()[0]&0x40 == 0 {
+			require.False(t, torsion.InG1())
+			thrice := new(blst.P1)
+			thrice.FromAffine(torsion)
+			require.Equal(t, bls.InfiniteSignature[:48], thrice.MultAssign([]byte{3}, 2).ToAffine().Compress())
+			return torsion
+		}
+	}
+}
+
+// offsetPublicKeys adds a point of order 3 to the key at index plus and subtracts it from the key at index
+// minus: the sum of the keys is still in G1 while the two keys are not.
+func offsetPublicKeys(t *testing.T, pubKeys [][]byte, plus, minus int) {
+	torsion := torsionPoint(t)
+	point := new(blst.P1)
+	point.FromAffine(new(blst.P1Affine).Uncompress(pubKeys[plus]))
+	pubKeys[plus] = point.AddAssign(torsion).ToAffine().Compress()
+	point.FromAffine(new(blst.P1Affine).Uncompress(pubKeys[minus]))
+	pubKeys[minus] = point.SubAssign(torsion).ToAffine().Compress()
+}
+
+func TestNewPublicKeysFromBytes(t *testing.T) {
+	for _, n := range []int{10, 200} {
+		_, _, pubKeys := generateBatch(t, n)
+		publicKeys, err := bls.NewPublicKeysFromBytes(pubKeys)
+		require.NoError(t, err)
+		for i := range pubKeys {
+			require.Equal(t, pubKeys[i], bls.CompressPublicKey(publicKeys[i]))
+		}
+
+		badPublicKey, _ := outsideSubgroup(t)
+		pubKeys[n-3] = badPublicKey
+		_, err = bls.NewPublicKeysFromBytes(pubKeys)
+		require.ErrorIs(t, err, bls.ErrNotGroupPublicKey)
+		require.Contains(t, err.Error(), fmt.Sprintf("public key %d", n-3))
+
+		pubKeys[n-3] = bls.InfiniteSignature[:48]
+		_, err = bls.NewPublicKeysFromBytes(pubKeys)
+		require.ErrorIs(t, err, bls.ErrInfinitePublicKey)
+	}
+}
+
+func TestBatchSubgroupCheckCancellingKeys(t *testing.T) {
+	_, _, pubKeys := generateBatch(t, 200)
+	offsetPublicKeys(t, pubKeys, 5, 150)
+	sum := new(blst.P1)
+	for _, pubKey := range pubKeys {
+		sum.AddAssign(new(blst.P1Affine).Uncompress(pubKey))
+	}
+	require.True(t, sum.ToAffine().InG1())
+
+	_, err := bls.AggregatePublickKeys(pubKeys)
+	require.ErrorIs(t, err, bls.ErrNotGroupPublicKey)
+	require.Contains(t, err.Error(), "public key 5")
+
+	bls.SetDeterministicBatchRandomness()
+	defer bls.SetBatchRandomness(nil)
+	_, err = bls.NewPublicKeysFromBytes(pubKeys)
+	require.ErrorIs(t, err, bls.ErrNotGroupPublicKey)
+}
+
+func TestNewSignaturesFromBytes(t *testing.T) {
+	for _, n := range []int{10, 100} {
+		sigs, msgs, pubKeys := generateBatch(t, n)
+		signatures, err := bls.NewSignaturesFromBytes(sigs)
+		require.NoError(t, err)
+		for i := range sigs {
+			require.Equal(t, sigs[i], signatures[i].Bytes())
+		}
+
+		_, badSignature := outsideSubgroup(t)
+		sigs[n/2] = badSignature
+		_, err = bls.NewSignaturesFromBytes(sigs)
+		require.ErrorIs(t, err, bls.ErrNotGroupSignature)
+		require.Contains(t, err.Error(), fmt.Sprintf("signature %d", n/2))
+		_, err = bls.AggregateSignatures(sigs)
+		require.ErrorIs(t, err, bls.ErrNotGroupSignature)
+
+		valid, err := bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+		require.NoError(t, err)
+		require.False(t, valid)
+		found, err := bls.VerifyMultipleSignaturesBisect(sigs, msgs, pubKeys)
+		require.NoError(t, err)
+		require.Equal(t, []int{n / 2}, found)
+
+		sigs[n/2] = sigs[n/2][:95]
+		_, err = bls.NewSignaturesFromBytes(sigs)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), fmt.Sprintf("signature %d", n/2))
+	}
+}
+
+func TestVerifyMultipleSignaturesBisectInvalidPublicKeys(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 200)
+	offsetPublicKeys(t, pubKeys, 10, 20)
+	found, err := bls.VerifyMultipleSignaturesBisect(sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.Equal(t, []int{10, 20}, found)
+
+	_, err = bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	require.ErrorIs(t, err, bls.ErrNotGroupPublicKey)
+}
+
+func TestLoadPublicKeysIntoCache(t *testing.T) {
+	_, _, pubKeys := generateBatch(t, 200)
+	require.ErrorIs(t, bls.LoadPublicKeysIntoCache(pubKeys, true), bls.ErrCacheNotEnabled)
+
+	bls.SetEnabledCaching(true)
+	defer bls.SetEnabledCaching(false)
+	defer bls.ClearCache()
+	require.NoError(t, bls.LoadPublicKeysIntoCache(pubKeys, true))
+	offsetPublicKeys(t, pubKeys, 0, 1)
+	require.ErrorIs(t, bls.LoadPublicKeysIntoCache(pubKeys, true), bls.ErrNotGroupPublicKey)
+}
+
+func BenchmarkNewSignaturesFromBytes(b *testing.B) {
+	sigs, _, _ := generateBatch(b, 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bls.NewSignaturesFromBytes(sigs)
+	}
+}
+
+// BenchmarkNewSignatureFromBytesOneByOne is the baseline for BenchmarkNewSignaturesFromBytes.
+func BenchmarkNewSignatureFromBytesOneByOne(b *testing.B) {
+	sigs, _, _ := generateBatch(b, 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, sig := range sigs {
+			bls.NewSignatureFromBytes(sig)
+		}
+	}
+}
+
+func BenchmarkNewPublicKeysFromBytes(b *testing.B) {
+	_, _, pubKeys := generateBatch(b, 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bls.NewPublicKeysFromBytes(pubKeys)
+	}
+}
+
+// BenchmarkNewPublicKeyFromBytesOneByOne is the baseline for BenchmarkNewPublicKeysFromBytes.
+func BenchmarkNewPublicKeyFromBytesOneByOne(b *testing.B) {
+	_, _, pubKeys := generateBatch(b, 1024)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, pubKey := range pubKeys {
+			bls.NewPublicKeyFromBytes(pubKey)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package bls
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+	"sync/atomic"
+)
+
+// verificationCache remembers the (signature, message, public keys) tuples which verified, so that duplicate
+// gossip is not verified again. The least recently used tuple is evicted first. Failures are never cached.
+type verificationCache struct {
+	enabled atomic.Bool
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+
+	mu      sync.Mutex
+	size    int
+	entries map[[32]byte]*list.Element
+	order   *list.List
+}
+
+var verifications = &verificationCache{}
+
+// SetVerificationCacheSize enables a cache of the last size successful verifications, consulted by Verify,
+// VerifyAggregate and the batch verifications. 0 disables it. The cache and its counters are reset.
+func SetVerificationCacheSize(size int) {
+	verifications.mu.Lock()
+	defer verifications.mu.Unlock()
+	verifications.size = max(size, 0)
+	verifications.entries = make(map[[32]byte]*list.Element)
+	verifications.order = list.New()
+	verifications.hits.Store(0)
+	verifications.misses.Store(0)
+	verifications.enabled.Store(size > 0)
+}
+
+// VerificationCacheStats returns the number of verifications answered by the cache and of those which were not.
+func VerificationCacheStats() (hits uint64, misses uint64) {
+	return verifications.hits.Load(), verifications.misses.Load()
+}
+
+// verificationKey hashes a tuple, dst tells the ciphersuite apart.
+func verificationKey(dst []byte, signature []byte, msg []byte, publicKeys ...[]byte) [32]byte {
+	h := sha256.New()
+	writeTranscript(h, dst, signature, msg)
+	writeTranscript(h, publicKeys...)
+	var key [32]byte
+	h.Sum(key[:0])
+	return key
+}
+
+// lookup returns the key of a tuple and whether it verified before. Nothing is counted when the cache is disabled.
+func (c *verificationCache) lookup(dst []byte, signature []byte, msg []byte, publicKeys ...[]byte) ([32]byte, bool) {
+	if !c.enabled.Load() {
+		return [32]byte{}, false
+	}
+	key := verificationKey(dst, signature, msg, publicKeys...)
+	return key, c.contains(key)
+}
+
+func (c *verificationCache) contains(key [32]byte) bool {
+	c.mu.Lock()
+	element, ok := c.entries[key]
+	if ok {
+		c.order.MoveToFront(element)
+	}
+	c.mu.Unlock()
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return ok
+}
+
+// lookupBatch returns the keys of the tuples of a batch which did not verify before, along with their indices.
+// keys is nil when the cache is disabled.
+func (c *verificationCache) lookupBatch(dst []byte, sigs [][]byte, msgs [][]byte, pubKeys [][]byte) (keys [][32]byte, remaining []int) {
+	if !c.enabled.Load() {
+		return nil, nil
+	}
+	keys = make([][32]byte, 0, len(sigs))
+	for i := range sigs {
+		key := verificationKey(dst, sigs[i], msgs[i], pubKeys[i])
+		if !c.contains(key) {
+			keys = append(keys, key)
+			remaining = append(remaining, i)
+		}
+	}
+	return keys, remaining
+}
+
+// verifyBatch runs verify on the tuples of a batch which did not verify before, and caches them if they all
+// verify. augs are optional message prefixes.
+func (c *verificationCache) verifyBatch(dst []byte, sigs, msgs, pubKeys, augs [][]byte, verify func(sigs, msgs, pubKeys, augs [][]byte) (bool, error)) (bool, error) {
+	return c.verifyRemaining(dst, sigs, msgs, pubKeys, func(remaining []int) (bool, error) {
+		if remaining == nil {
+			return verify(sigs, msgs, pubKeys, augs)
+		}
+		if augs != nil {
+			augs = pick(augs, remaining)
+		}
+		return verify(pick(sigs, remaining), pick(msgs, remaining), pick(pubKeys, remaining), augs)
+	})
+}
+
+// verifyRemaining is verifyBatch for callers picking the tuples themselves, verify is given the indices of the
+// tuples which did not verify before, or nil for the whole batch when the cache is disabled.
+func (c *verificationCache) verifyRemaining(dst []byte, sigs, msgs, pubKeys [][]byte, verify func(remaining []int) (bool, error)) (bool, error) {
+	keys, remaining := c.lookupBatch(dst, sigs, msgs, pubKeys)
+	if keys == nil {
+		return verify(nil)
+	}
+	if len(remaining) == 0 {
+		return true, nil
+	}
+	valid, err := verify(remaining)
+	if valid {
+		c.add(keys...)
+	}
+	return valid, err
+}
+
+// add remembers that the tuples of keys verified.
+func (c *verificationCache) add(keys ...[32]byte) {
+	if !c.enabled.Load() {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if element, ok := c.entries[key]; ok {
+			c.order.MoveToFront(element)
+			continue
+		}
+		c.entries[key] = c.order.PushFront(key)
+		if c.order.Len() > c.size {
+			delete(c.entries, c.order.Remove(c.order.Back()).([32]byte))
+		}
+	}
+}
+
+// pick returns the elements of s at indices.
+func pick[T any](s []T, indices []int) []T {
+	picked := make([]T, len(indices))
+	for j, i := range indices {
+		picked[j] = s[i]
+	}
+	return picked
+}
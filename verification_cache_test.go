@@ -0,0 +1,175 @@
+package bls_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerificationCache(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 2)
+
+	// Disabled by default.
+	valid, err := bls.Verify(sigs[0], msgs[0], pubKeys[0])
+	require.NoError(t, err)
+	require.True(t, valid)
+	hits, misses := bls.VerificationCacheStats()
+	require.Zero(t, hits)
+	require.Zero(t, misses)
+
+	bls.SetVerificationCacheSize(16)
+	defer bls.SetVerificationCacheSize(0)
+
+	for i := 0; i < 3; i++ {
+		valid, err = bls.Verify(sigs[0], msgs[0], pubKeys[0])
+		require.NoError(t, err)
+		require.True(t, valid)
+	}
+	hits, misses = bls.VerificationCacheStats()
+	require.Equal(t, uint64(2), hits)
+	require.Equal(t, uint64(1), misses)
+
+	// Failures are never cached.
+	for i := 0; i < 2; i++ {
+		valid, err = bls.Verify(sigs[0], msgs[1], pubKeys[0])
+		require.NoError(t, err)
+		require.False(t, valid)
+	}
+	hits, misses = bls.VerificationCacheStats()
+	require.Equal(t, uint64(2), hits)
+	require.Equal(t, uint64(3), misses)
+
+	// A cached tuple does not answer for another message or public key.
+	valid, err = bls.Verify(sigs[0], msgs[0], pubKeys[1])
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	valid, err = bls.VerifyAggregate(sigs[1], msgs[1], [][]byte{pubKeys[1]})
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, err = bls.VerifyAggregate(sigs[1], msgs[1], [][]byte{pubKeys[1]})
+	require.NoError(t, err)
+	require.True(t, valid)
+	hits, _ = bls.VerificationCacheStats()
+	require.Equal(t, uint64(3), hits)
+}
+
+func TestVerificationCacheEviction(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 3)
+	bls.SetVerificationCacheSize(2)
+	defer bls.SetVerificationCacheSize(0)
+
+	verify := func(i int) {
+		valid, err := bls.Verify(sigs[i], msgs[i], pubKeys[i])
+		require.NoError(t, err)
+		require.True(t, valid)
+	}
+	verify(0)
+	verify(1)
+	// 0 becomes the most recently used, so 1 is evicted by 2.
+	verify(0)
+	verify(2)
+	hits, misses := bls.VerificationCacheStats()
+	require.Equal(t, uint64(1), hits)
+	require.Equal(t, uint64(3), misses)
+
+	verify(0)
+	verify(1)
+	hits, misses = bls.VerificationCacheStats()
+	require.Equal(t, uint64(2), hits)
+	require.Equal(t, uint64(4), misses)
+}
+
+func TestVerificationCacheBatch(t *testing.T) {
+	sigs, msgs, pubKeys := generateBatch(t, 8)
+	bls.SetVerificationCacheSize(64)
+	defer bls.SetVerificationCacheSize(0)
+
+	valid, err := bls.VerifyMultipleSignatures(sigs[:4], msgs[:4], pubKeys[:4])
+	require.NoError(t, err)
+	require.True(t, valid)
+	valid, err = bls.VerifyMultipleSignatures(sigs, msgs, pubKeys)
+	require.NoError(t, err)
+	require.True(t, valid)
+	hits, misses := bls.VerificationCacheStats()
+	require.Equal(t, uint64(4), hits)
+	require.Equal(t, uint64(8), misses)
+
+	// A cached tuple does not make a batch with an invalid one valid.
+	badMsgs := [][]byte{[]byte("tampered"), msgs[1]}
+	valid, err = bls.VerifyMultipleSignatures(sigs[:2], badMsgs, pubKeys[:2])
+	require.NoError(t, err)
+	require.False(t, valid)
+
+	// Bisection reports the indices of the whole batch and caches the valid tuples.
+	bisectSigs, bisectMsgs, bisectPubKeys := generateBatch(t, 4, 2)
+	bisectSigs = append(bisectSigs, sigs[0])
+	bisectMsgs = append(bisectMsgs, msgs[0])
+	bisectPubKeys = append(bisectPubKeys, pubKeys[0])
+	invalid, err := bls.VerifyMultipleSignaturesBisect(bisectSigs, bisectMsgs, bisectPubKeys)
+	require.NoError(t, err)
+	require.Equal(t, []int{2}, invalid)
+	invalid, err = bls.VerifyMultipleSignaturesBisect(bisectSigs, bisectMsgs, bisectPubKeys)
+	require.NoError(t, err)
+	require.Equal(t, []int{2}, invalid)
+	for i, sig := range bisectSigs {
+		valid, err = bls.Verify(sig, bisectMsgs[i], bisectPubKeys[i])
+		require.NoError(t, err)
+		require.Equal(t, i != 2, valid)
+	}
+	hits, _ = bls.VerificationCacheStats()
+	// 4 + 1 in the batches, 1 + 4 in the bisections and 4 in the last verifications.
+	require.Equal(t, uint64(14), hits)
+}
+
+func TestVerificationCacheSignatureSets(t *testing.T) {
+	privateKey, err := bls.GenerateKey()
+	require.NoError(t, err)
+	publicKey := privateKey.PublicKey()
+	msg := []byte("attestation data")
+	infinite := bls.AddPublicKeys(publicKey, bls.NegatePublicKey(publicKey))
+	bls.SetVerificationCacheSize(16)
+	defer bls.SetVerificationCacheSize(0)
+
+	v := bls.NewBatchVerifier(1, time.Millisecond)
+	defer v.Close()
+	verifySet := func(set *bls.SignatureSet) bool {
+		result := make(chan bool, 1)
+		require.NoError(t, v.VerifySetLaneCallback(0, set, func(valid bool, err error) {
+			require.NoError(t, err)
+			result <- valid
+		}))
+		return <-result
+	}
+	compress := func(publicKeys ...bls.PublicKey) [][]byte {
+		encoded := make([][]byte, len(publicKeys))
+		for i, pk := range publicKeys {
+			encoded[i] = bls.CompressPublicKey(pk)
+		}
+		return encoded
+	}
+
+	// Keys cancelling each other, rejected by both.
+	signature, err := bls.NewSignatureFromBytes(bls.InfiniteSignature[:])
+	require.NoError(t, err)
+	cancelling := []bls.PublicKey{publicKey, bls.NegatePublicKey(publicKey)}
+	for i := 0; i < 2; i++ {
+		require.False(t, verifySet(&bls.SignatureSet{Signature: signature, Message: msg, PublicKeys: cancelling}))
+		valid, _ := bls.VerifyAggregate(bls.InfiniteSignature[:], msg, compress(cancelling...))
+		require.False(t, valid)
+	}
+
+	// A point at infinity among the keys of a set is aggregated, VerifyAggregate rejects it.
+	withInfinity := []bls.PublicKey{infinite, publicKey}
+	set := &bls.SignatureSet{Signature: privateKey.Sign(msg), Message: msg, PublicKeys: withInfinity}
+	require.True(t, verifySet(set))
+	valid, err := bls.VerifyAggregate(set.Signature.Bytes(), msg, compress(withInfinity...))
+	require.ErrorIs(t, err, bls.ErrInfinitePublicKey)
+	require.False(t, valid)
+	require.True(t, verifySet(set))
+
+	hits, _ := bls.VerificationCacheStats()
+	require.Equal(t, uint64(1), hits)
+}
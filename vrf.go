@@ -0,0 +1,41 @@
+package bls
+
+import (
+	"crypto/sha256"
+
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// A BLS signature is unique and deterministic for a given key and message, so it is a VRF proof as is:
+// the output is a hash of the proof, which anyone holding the public key can check.
+
+// The VRF never shares its DST with signatures, a proof can't be replayed as a signature nor the opposite.
+var vrfCurve = []byte("BLS_VRF_BLS12381G2_XMD:SHA-256_SSWU_RO_")
+
+var vrfOutputDST = []byte("BLS_VRF_OUTPUT_")
+
+func vrfOutput(proof []byte) []byte {
+	h := sha256.New()
+	h.Write(vrfOutputDST)
+	h.Write(proof)
+	return h.Sum(nil)
+}
+
+// VRFProve evaluates the VRF on alpha, returning the proof and the output.
+func VRFProve(sk *PrivateKey, alpha []byte) (proof []byte, output []byte) {
+	proof = new(blst.P2Affine).Sign(sk.key, alpha, vrfCurve).Compress()
+	return proof, vrfOutput(proof)
+}
+
+// VRFVerify checks a proof of the VRF evaluated on alpha and returns the output.
+func VRFVerify(pk PublicKey, alpha []byte, proof []byte) ([]byte, error) {
+	sig, err := NewSignatureFromBytes(proof)
+	if err != nil {
+		return nil, err
+	}
+	// The proof is only unique for keys in the group.
+	if !sig.affine.Verify(false, pk, true, alpha, vrfCurve) {
+		return nil, ErrInvalidVRFProof
+	}
+	return vrfOutput(proof), nil
+}
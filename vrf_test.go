@@ -0,0 +1,100 @@
+package bls_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/Giulio2002/bls"
+	"github.com/stretchr/testify/require"
+	blst "github.com/supranational/blst/bindings/go"
+)
+
+// There are no published vectors for this VRF. The proofs and outputs below were computed with
+// github.com/kilic/bls12-381, an implementation independent of blst, as the hash of alpha to G2 under
+// vrfDST times the private key, and SHA-256 of vrfOutputDST followed by the compressed proof.
+var vrfKnownAnswers = []struct {
+	privateKey string
+	alpha      string
+	proof      string
+	output     string
+}{
+	{
+		privateKey: "263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3",
+		alpha:      "",
+		proof:      "a1c3cc6b3bdc9a20a3a750ac526db6092a1983cfbe3ef59885761662ae99964a5db366356f3f427c341106bc5c5d80020c30d304e2e76e20e61d4fe3eabab4698bd439126ae472f80b2fb9cedff91351fe5c49b1e8bbd93e59fb14af7047049d",
+		output:     "0f20bf5cd7d6a8ed66aaaaca773452d0d9232aff3f3a11ddadec3cddec8a0a46",
+	},
+	{
+		privateKey: "47b8192d77bf871b62e87859d653922725724a5c031afeabc60bcef5ff665138",
+		alpha:      "5656565656565656565656565656565656565656565656565656565656565656",
+		proof:      "af95f824825bd26c0ebe4faedf383646c57e8e26cfcf4242b06c05321d1760352d1b98c0344afbe5025c75eae5f6cf1a0afde8a3ef98e2cc08234967d53d3ead03b0b885375ebec2df98e824d09f81b37e913d7bbc077a95db20bb7e94aee84f",
+		output:     "9dfcd2abd08fa81e0cf4af54d0d0ca33559df9ad0b7c257df3dc742923d90e38",
+	},
+	{
+		privateKey: "328388aff0d4a5b7dc9205abd374e7e98f3cd9f3418edb4eafda5fb16473d216",
+		alpha:      "abababababababababababababababababababababababababababababababab",
+		proof:      "b3fc63802fe47209a9643e3ccf5a5ead5812d498d521d3b73ce60b500740058c9b1b973b51988fadbe23eea935a70d80067056d243d523996f393bca79246e50589f344395292f1daba74f3c44ce52c29d5e81f151c0a7c90fac3369fcf6fcaf",
+		output:     "d837be99eb3968dd75a93b64480a2c5984c982ad072a168e79ac1d6f94dc5cf5",
+	},
+}
+
+var (
+	vrfDST       = []byte("BLS_VRF_BLS12381G2_XMD:SHA-256_SSWU_RO_")
+	vrfOutputDST = []byte("BLS_VRF_OUTPUT_")
+)
+
+// vrfReference evaluates the VRF from its definition with the curve primitives rather than through bls.
+func vrfReference(privateKey []byte, alpha []byte) (proof []byte, output []byte) {
+	proof = blst.HashToG2(alpha, vrfDST).Mult(new(blst.Scalar).Deserialize(privateKey)).ToAffine().Compress()
+	sum := sha256.Sum256(append(append([]byte{}, vrfOutputDST...), proof...))
+	return proof, sum[:]
+}
+
+func TestVRFKnownAnswers(t *testing.T) {
+	for _, v := range vrfKnownAnswers {
+		referenceProof, referenceOutput := vrfReference(convertHexToPrivateKey(v.privateKey), convertHexToMessage(v.alpha))
+		require.Equal(t, convertHexToSignature(v.proof), referenceProof)
+		require.Equal(t, convertHexToMessage(v.output), referenceOutput)
+
+		privateKey, err := bls.NewPrivateKeyFromBytes(convertHexToPrivateKey(v.privateKey))
+		require.NoError(t, err)
+		alpha := convertHexToMessage(v.alpha)
+
+		proof, output := bls.VRFProve(privateKey, alpha)
+		require.Equal(t, convertHexToSignature(v.proof), proof)
+		require.Equal(t, convertHexToMessage(v.output), output)
+
+		verified, err := bls.VRFVerify(privateKey.PublicKey(), alpha, proof)
+		require.NoError(t, err)
+		require.Equal(t, output, verified)
+	}
+}
+
+func TestVRFUniqueness(t *testing.T) {
+	privateKey, err := bls.GenerateKey()
+	require.NoError(t, err)
+	alpha := convertHexToMessage("5656565656565656565656565656565656565656565656565656565656565656")
+
+	// Evaluating twice gives the same proof and output.
+	proof, output := bls.VRFProve(privateKey, alpha)
+	proof2, output2 := bls.VRFProve(privateKey, alpha)
+	require.Equal(t, proof, proof2)
+	require.Equal(t, output, output2)
+
+	// Different inputs and keys give different outputs.
+	_, otherOutput := bls.VRFProve(privateKey, convertHexToMessage("abababababababababababababababababababababababababababababababab"))
+	require.NotEqual(t, output, otherOutput)
+	otherKey, err := bls.GenerateKey()
+	require.NoError(t, err)
+	otherProof, otherOutput := bls.VRFProve(otherKey, alpha)
+	require.NotEqual(t, output, otherOutput)
+
+	// No other proof is accepted for the same key and input.
+	_, err = bls.VRFVerify(privateKey.PublicKey(), alpha, otherProof)
+	require.ErrorIs(t, err, bls.ErrInvalidVRFProof)
+	_, err = bls.VRFVerify(privateKey.PublicKey(), alpha, bls.InfiniteSignature[:])
+	require.ErrorIs(t, err, bls.ErrInvalidVRFProof)
+	// Nor is an ordinary signature of the input.
+	_, err = bls.VRFVerify(privateKey.PublicKey(), alpha, privateKey.Sign(alpha).Bytes())
+	require.ErrorIs(t, err, bls.ErrInvalidVRFProof)
+}